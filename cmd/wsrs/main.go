@@ -4,36 +4,85 @@ import (
 	"context"   // Pacote para manipulação de contexto, que é útil para controlar cancelamentos e deadlines em operações.
 	"errors"    // Pacote para manipulação de erros.
 	"fmt"       // Pacote para formatação de strings.
+	"io/fs"     // Pacote com os erros de sistema de arquivos, usado para ignorar a ausência do arquivo .env.
+	"log/slog"  // Pacote para logs estruturados.
 	"net/http"  // Pacote para criação de servidores HTTP.
 	"os"        // Pacote para interação com o sistema operacional, como leitura de variáveis de ambiente e manipulação de sinais.
 	"os/signal" // Pacote para captura de sinais do sistema operacional, como interrupções.
+	"syscall"   // Pacote com as constantes dos sinais do sistema operacional, como SIGTERM.
 
+	"github.com/google/uuid"                                  // Pacote para ler o ID da sala do subcomando moderator-token.
+	"github.com/jackc/pgx/v5"                                 // Pacote com os erros do driver do PostgreSQL.
 	"github.com/jackc/pgx/v5/pgxpool"                         // Pacote para gerenciar um pool de conexões ao banco de dados PostgreSQL.
 	"github.com/joao-ressel/go-server/internal/api"           // Pacote interno que contém o manipulador (handler) da API.
+	"github.com/joao-ressel/go-server/internal/certs"         // Pacote interno que carrega e recarrega o certificado TLS.
+	"github.com/joao-ressel/go-server/internal/config"        // Pacote interno que carrega a configuração do servidor.
+	"github.com/joao-ressel/go-server/internal/filter"        // Pacote interno com o filtro de conteúdo das mensagens.
+	"github.com/joao-ressel/go-server/internal/metrics"       // Pacote interno com as métricas expostas em /metrics.
 	"github.com/joao-ressel/go-server/internal/store/pgstore" // Pacote interno que gerencia a interação com o banco de dados.
+	"github.com/joao-ressel/go-server/internal/tracing"       // Pacote interno que configura o OpenTelemetry.
+	"github.com/joao-ressel/go-server/internal/webhook"       // Pacote interno que entrega os eventos aos webhooks das salas.
 	"github.com/joho/godotenv"                                // Pacote para carregar variáveis de ambiente de um arquivo .env.
 )
 
 func main() {
-	// Carrega as variáveis de ambiente do arquivo .env.
-	// Se houver erro durante o carregamento, o programa dispara um pânico.
-	if err := godotenv.Load(); err != nil {
+	// Carrega as variáveis de ambiente do arquivo .env, se ele existir.
+	// Se o arquivo existir mas não puder ser lido, o programa dispara um pânico.
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		panic(err)
+	}
+
+	// O subcomando "config print" exibe a configuração efetiva e encerra o programa.
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		if len(args) < 2 || args[1] != "print" {
+			fmt.Fprintln(os.Stderr, "usage: wsrs config print [flags]")
+			os.Exit(2)
+		}
+
+		cfg, err := config.Load(args[2:], os.Getenv)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	// O subcomando "moderator-token" emite um novo token de moderador para a sala e encerra o programa.
+	// Serve às salas criadas antes dos tokens de moderador e à troca de um token perdido ou vazado.
+	if len(args) > 0 && args[0] == "moderator-token" {
+		if err := rotateModeratorToken(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Monta a configuração a partir do arquivo opcional, das variáveis de ambiente e das flags.
+	// Se a configuração for inválida, o programa dispara um pânico.
+	cfg, err := config.Load(args, os.Getenv)
+	if err != nil {
 		panic(err)
 	}
 
 	// Cria um contexto de fundo (background) que pode ser utilizado para controlar a vida útil das operações.
 	ctx := context.Background()
 
-	// Cria uma nova pool de conexões com o banco de dados PostgreSQL usando as variáveis de ambiente.
-	pool, err := pgxpool.New(ctx, fmt.Sprintf(
-		"user=%s password=%s host=%s port=%s dbname=%s",
-		os.Getenv("WSRS_DATABASE_USER"),
-		os.Getenv("WSRS_DATABASE_PASSWORD"),
-		os.Getenv("WSRS_DATABASE_HOST"),
-		os.Getenv("WSRS_DATABASE_PORT"),
-		os.Getenv("WSRS_DATABASE_NAME"),
-	))
-	// Se ocorrer um erro ao criar a pool de conexões, o programa dispara um pânico.
+	// Configura o envio de spans do OpenTelemetry.
+	// O exportador pode ser "otlp" (coletor local, configurável via OTEL_EXPORTER_OTLP_ENDPOINT) ou "stdout".
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter)
+	if err != nil {
+		panic(err)
+	}
+	// Garante que os spans pendentes serão enviados quando o main terminar.
+	defer func() { _ = shutdownTracing(context.Background()) }()
+
+	// Cria a pool de conexões com o banco de dados PostgreSQL e verifica se a conexão está ativa.
+	// Se a pool não puder ser criada ou a verificação falhar, o programa dispara um pânico.
+	pool, err := openPool(ctx, cfg.Database)
 	if err != nil {
 		panic(err)
 	}
@@ -41,27 +90,197 @@ func main() {
 	// Garante que a pool de conexões será fechada quando o main terminar.
 	defer pool.Close()
 
-	// Verifica se a conexão com o banco de dados está ativa.
-	// Se a verificação falhar, o programa dispara um pânico.
-	if err := pool.Ping(ctx); err != nil {
-		panic(err)
+	// Expõe as estatísticas da pool de conexões nas métricas do Prometheus.
+	metrics.Registry.MustRegister(metrics.NewPoolCollector(pool))
+
+	// Carrega a lista padrão do filtro de conteúdo.
+	// Se um arquivo estiver configurado, a lista é lida dele em vez da lista embutida.
+	rules := filter.DefaultRules()
+	if cfg.FilterWordlist != "" {
+		if rules, err = filter.LoadFile(cfg.FilterWordlist); err != nil {
+			panic(err)
+		}
 	}
 
 	// Cria um novo handler da API utilizando a store de banco de dados criada (pgstore).
-	handler := api.NewHandler(pgstore.New(pool))
+	q := pgstore.New(pool)
+	opts := []api.Option{
+		api.WithContentFilter(filter.New(q, rules)),
+		api.WithDatabase(pool),
+		api.WithReadinessTimeout(cfg.HTTP.ReadinessTimeout),
+		api.WithRateLimit(cfg.Limits.RateLimit),
+		api.WithReportThreshold(cfg.Limits.ReportThreshold),
+		api.WithAllowedOrigins(cfg.AllowedOrigins),
+	}
+	// Sem uma chave configurada, os tokens de participante valem apenas até o servidor reiniciar.
+	if cfg.ParticipantKey != "" {
+		opts = append(opts, api.WithParticipantKey([]byte(cfg.ParticipantKey)))
+	} else {
+		slog.Warn("participant_key is not set, participant tokens will not survive a restart")
+	}
+	handler := api.NewHandler(q, opts...)
+
+	// Inicia o envio das entregas de webhooks pendentes, que é interrompido no encerramento do servidor.
+	// Entregas interrompidas no meio voltam para a fila e são enviadas depois, por esta ou por outra instância.
+	dispatcherCtx, stopDispatcher := context.WithCancel(ctx)
+	defer stopDispatcher()
+	go webhook.NewDispatcher(q, webhook.Config{
+		Timeout:              cfg.Webhooks.Timeout,
+		MaxAttempts:          cfg.Webhooks.MaxAttempts,
+		AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+	}).Run(dispatcherCtx)
 
-	// Inicia o servidor HTTP em uma nova goroutine para escutar requisições na porta 8080.
+	// Cria o servidor HTTP que escuta requisições no endereço configurado.
+	srv := &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// Se o TLS estiver configurado, carrega o certificado e o recarrega ao receber SIGHUP ou quando os arquivos mudarem.
+	// Se o certificado não puder ser carregado, o programa dispara um pânico.
+	var redirectSrv *http.Server
+	if cfg.TLS.Enabled() {
+		reloader, err := certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			panic(err)
+		}
+		srv.TLSConfig = reloader.TLSConfig()
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := reloader.Reload(); err != nil {
+					slog.Error("failed to reload tls certificate", "error", err)
+				}
+			}
+		}()
+
+		if cfg.TLS.ReloadInterval > 0 {
+			go reloader.Watch(ctx, cfg.TLS.ReloadInterval)
+		}
+
+		// Servidor HTTP opcional que apenas redireciona para o HTTPS.
+		if cfg.TLS.RedirectAddr != "" {
+			redirectSrv = &http.Server{
+				Addr:              cfg.TLS.RedirectAddr,
+				Handler:           certs.RedirectHandler(cfg.ListenAddr),
+				ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+			}
+			go func() {
+				if err := redirectSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					panic(err)
+				}
+			}()
+		}
+	}
+
+	// Inicia o servidor HTTP (ou HTTPS, com HTTP/2) em uma nova goroutine.
 	// Se o servidor falhar ao iniciar (exceto se for um erro de fechamento do servidor), o programa dispara um pânico.
 	go func() {
-		if err := http.ListenAndServe(":8080", handler); err != nil {
+		var err error
+		if srv.TLSConfig != nil {
+			err = srv.ListenAndServeTLS("", "") // O certificado vem de TLSConfig.GetCertificate
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil {
 			if !errors.Is(err, http.ErrServerClosed) {
 				panic(err)
 			}
 		}
 	}()
 
-	// Cria um canal que captura sinais do sistema operacional, como uma interrupção (Ctrl+C).
+	// Cria um canal que captura sinais do sistema operacional, como uma interrupção (Ctrl+C) ou o SIGTERM do orquestrador.
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit // Bloqueia até que uma interrupção seja recebida.
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit // Bloqueia até que um sinal seja recebido.
+
+	slog.Info("shutting down", "grace_period", cfg.HTTP.ShutdownGracePeriod)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownGracePeriod)
+	defer cancel()
+
+	// Avisa e desconecta os assinantes WebSocket, que não são acompanhados pelo http.Server após o upgrade.
+	if err := handler.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("failed to drain websocket subscribers", "error", err)
+	}
+
+	// Para de aceitar conexões e aguarda a conclusão das requisições em andamento.
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("failed to drain http requests", "error", err)
+	}
+	if redirectSrv != nil {
+		_ = redirectSrv.Shutdown(shutdownCtx)
+	}
+}
+
+// openPool cria a pool de conexões com o banco de dados e verifica se a conexão está ativa.
+func openPool(ctx context.Context, db config.DatabaseConfig) (*pgxpool.Pool, error) {
+	// Monta a configuração da pool de conexões com o banco de dados PostgreSQL.
+	poolConfig, err := pgxpool.ParseConfig(db.DSN())
+	if err != nil {
+		return nil, err
+	}
+
+	// Aplica os tamanhos da pool, se configurados.
+	if db.MaxConns > 0 {
+		poolConfig.MaxConns = db.MaxConns
+	}
+	if db.MinConns > 0 {
+		poolConfig.MinConns = db.MinConns
+	}
+
+	// Cria um span para cada consulta executada pela pool.
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// rotateModeratorToken executa o subcomando "moderator-token <room_id> [flags]", que imprime o novo token da sala.
+// O token anterior, se houver, deixa de valer.
+func rotateModeratorToken(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: wsrs moderator-token <room_id> [flags]")
+	}
+
+	roomID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid room id: %w", err)
+	}
+
+	cfg, err := config.Load(args[1:], os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pool, err := openPool(ctx, cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	token, err := api.RotateModeratorToken(ctx, pgstore.New(pool), roomID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("room not found")
+		}
+		return err
+	}
+
+	fmt.Println(token)
+	return nil
 }
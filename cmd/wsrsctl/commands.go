@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/pkg/client"
+)
+
+// Cores ANSI usadas no tail
+const (
+	colorReset  = "\x1b[0m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorRed    = "\x1b[31m"
+	colorGray   = "\x1b[90m"
+)
+
+func (app cli) paint(color, s string) string {
+	if !app.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// rooms executa os subcomandos de salas.
+func (app cli) rooms(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: wsrsctl rooms list|create|get|clone|import")
+	}
+
+	switch args[0] {
+	case "list":
+		rooms, err := app.client.GetRooms(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(app.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tMODERATED\tTHEME")
+		for _, room := range rooms {
+			fmt.Fprintf(tw, "%s\t%t\t%s\n", room.ID, room.Moderated, room.Theme)
+		}
+		return tw.Flush()
+
+	case "create":
+		fs := flag.NewFlagSet("rooms create", flag.ContinueOnError)
+		moderated := fs.Bool("moderated", false, "hold new messages for approval")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			return errors.New("usage: wsrsctl rooms create [-moderated] <theme>")
+		}
+
+		result, err := app.client.CreateRoom(ctx, client.CreateRoomParams{Theme: strings.Join(fs.Args(), " "), Moderated: *moderated})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "room:            %s\nmoderator token: %s\n", result.ID, result.ModeratorToken)
+		return nil
+
+	case "get":
+		roomID, err := argID(args[1:], 0, "room_id")
+		if err != nil {
+			return err
+		}
+		room, err := app.client.GetRoomDetails(ctx, roomID)
+		if err != nil {
+			return err
+		}
+		return app.printJSON(room)
+
+	case "clone":
+		fs := flag.NewFlagSet("rooms clone", flag.ContinueOnError)
+		theme := fs.String("theme", "", "theme of the new room (default: same as the source room)")
+		carryOver := fs.Bool("carry-over", false, "copy unanswered questions to the new room")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		roomID, err := argID(fs.Args(), 0, "room_id")
+		if err != nil {
+			return err
+		}
+
+		result, err := app.client.CloneRoom(ctx, roomID, client.CloneRoomParams{Theme: *theme, CarryOverUnanswered: *carryOver})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "room:            %s\nmoderator token: %s\nmessages copied: %d\n", result.ID, result.ModeratorToken, result.MessagesCopied)
+		return nil
+
+	case "import":
+		if len(args) < 2 {
+			return errors.New("usage: wsrsctl rooms import <file|->")
+		}
+		in := io.Reader(os.Stdin)
+		if args[1] != "-" {
+			f, err := os.Open(args[1])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+
+		result, err := app.client.ImportRoom(ctx, in)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "room:              %s\nmoderator token:   %s\nmessages imported: %d\n", result.ID, result.ModeratorToken, result.MessagesImported)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown rooms command %q", args[0])
+	}
+}
+
+// messages executa os subcomandos de mensagens.
+func (app cli) messages(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: wsrsctl messages list|post|answer|status|react|pin|unpin|now-answering")
+	}
+
+	// -as vem antes dos argumentos posicionais do post
+	var attribution string
+	if args[0] == "post" {
+		fs := flag.NewFlagSet("messages post", flag.ContinueOnError)
+		fs.StringVar(&attribution, "as", "", "attribution of the question (requires -api-key; default: the key name)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		args = append([]string{"post"}, fs.Args()...)
+	}
+
+	roomID, err := argID(args[1:], 0, "room_id")
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		messages, err := app.client.GetRoomMessages(ctx, roomID)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(app.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tREACTIONS\tSTATUS\tMODERATION\tMESSAGE")
+		for _, m := range messages {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", m.ID, m.ReactionCount, m.Status, m.ModerationStatus, m.Message)
+		}
+		return tw.Flush()
+
+	case "post":
+		if len(args) < 3 {
+			return errors.New("usage: wsrsctl messages post [-as source] <room_id> <text>")
+		}
+		result, err := app.client.CreateAttributedRoomMessage(ctx, roomID, strings.Join(args[2:], " "), attribution)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "%s (%s)\n", result.ID, result.ModerationStatus)
+		return nil
+
+	case "answer":
+		messageID, err := argID(args[1:], 1, "message_id")
+		if err != nil {
+			return err
+		}
+		return app.client.MarkMessageAsAnswered(ctx, roomID, messageID)
+
+	case "status":
+		messageID, err := argID(args[1:], 1, "message_id")
+		if err != nil {
+			return err
+		}
+		if len(args) < 4 {
+			return errors.New("usage: wsrsctl messages status <room_id> <message_id> <open|answering|answered|dismissed|duplicate>")
+		}
+		_, err = app.client.SetMessageStatus(ctx, roomID, messageID, args[3])
+		return err
+
+	case "react":
+		messageID, err := argID(args[1:], 1, "message_id")
+		if err != nil {
+			return err
+		}
+		count, err := app.client.ReactToMessage(ctx, roomID, messageID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(app.stdout, count)
+		return nil
+
+	case "pin", "unpin":
+		messageID, err := argID(args[1:], 1, "message_id")
+		if err != nil {
+			return err
+		}
+		if args[0] == "pin" {
+			_, err = app.client.PinMessage(ctx, roomID, messageID)
+		} else {
+			_, err = app.client.UnpinMessage(ctx, roomID, messageID)
+		}
+		return err
+
+	case "now-answering":
+		if len(args) < 3 { // Sem a mensagem, remove o destaque
+			_, err := app.client.ClearNowAnswering(ctx, roomID)
+			return err
+		}
+		messageID, err := argID(args[1:], 1, "message_id")
+		if err != nil {
+			return err
+		}
+		_, err = app.client.SetNowAnswering(ctx, roomID, messageID)
+		return err
+
+	default:
+		return fmt.Errorf("unknown messages command %q", args[0])
+	}
+}
+
+// participant emite uma identidade de participante; o token é usado depois com -participant-token.
+func (app cli) participant(ctx context.Context) error {
+	participant, err := app.client.CreateParticipant(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(app.stdout, "participant: %s\ntoken:       %s\n", participant.ParticipantID, participant.Token)
+	return nil
+}
+
+// tail acompanha os eventos de uma sala até que o comando seja interrompido.
+func (app cli) tail(ctx context.Context, args []string) error {
+	roomID, err := argID(args, 0, "room_id")
+	if err != nil {
+		return err
+	}
+
+	events, err := app.client.Subscribe(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(app.stdout, app.paint(colorGray, "following room "+roomID.String()+", press Ctrl+C to stop"))
+
+	for event := range events {
+		if event.Kind == "" && event.Err != nil {
+			return event.Err // A inscrição foi encerrada pelo servidor
+		}
+		fmt.Fprintln(app.stdout, app.formatEvent(event))
+	}
+	return nil
+}
+
+// formatEvent descreve um evento em uma linha, colorida conforme o tipo.
+func (app cli) formatEvent(event client.Event) string {
+	ts := app.paint(colorGray, time.Now().Format(time.TimeOnly))
+
+	var color, text string
+	switch v := event.Value.(type) {
+	case client.MessageCreated:
+		color, text = colorGreen, fmt.Sprintf("%s %q", v.ID, v.Message)
+		if v.Attribution != "" {
+			text += " via " + v.Attribution
+		}
+	case client.MessagePending:
+		color, text = colorYellow, fmt.Sprintf("%s %q", v.ID, v.Message)
+	case client.MessageReactionIncreased:
+		color, text = colorBlue, fmt.Sprintf("%s count=%d", v.ID, v.Count)
+	case client.MessageReactionDecreased:
+		color, text = colorBlue, fmt.Sprintf("%s count=%d", v.ID, v.Count)
+	case client.MessageAnswered:
+		color, text = colorGreen, v.ID
+	case client.MessageStatusChanged:
+		color, text = colorGreen, fmt.Sprintf("%s %s -> %s", v.ID, v.OldStatus, v.NewStatus)
+	case client.MessageRejected:
+		color, text = colorRed, v.ID
+	case client.MessageHidden:
+		color, text = colorRed, v.ID
+	case client.MessagePinned:
+		color, text = colorBlue, fmt.Sprintf("%s pinned=%t", v.ID, v.Pinned)
+	case client.NowAnswering:
+		color, text = colorGreen, "-"
+		if v.ID != "" {
+			text = fmt.Sprintf("%s %q", v.ID, v.Message)
+		}
+	case client.ServerShuttingDown:
+		color, text = colorYellow, v.Reason
+	default:
+		color, text = colorGray, string(event.Raw)
+	}
+
+	return ts + " " + app.paint(color, fmt.Sprintf("%-26s", event.Kind)) + " " + text
+}
+
+// export grava a sala e as suas mensagens no formato escolhido.
+func (app cli) export(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "export format: json, csv or md")
+	output := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	roomID, err := argID(fs.Args(), 0, "room_id")
+	if err != nil {
+		return err
+	}
+
+	body, err := app.client.ExportRoom(ctx, roomID, *format)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if *output == "" {
+		_, err = io.Copy(app.stdout, body)
+		return err
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (app cli) printJSON(v any) error {
+	enc := json.NewEncoder(app.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// argID lê o argumento posicional i como um UUID.
+func argID(args []string, i int, name string) (uuid.UUID, error) {
+	if len(args) <= i {
+		return uuid.UUID{}, fmt.Errorf("missing %s", name)
+	}
+	id, err := uuid.Parse(args[i])
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,120 @@
+// Comando wsrsctl opera as salas de um servidor em execução: cria e lista salas, envia e responde perguntas,
+// acompanha os eventos de uma sala em tempo real e exporta o conteúdo de uma sala.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joao-ressel/go-server/pkg/client"
+)
+
+const usage = `usage: wsrsctl [flags] <command> [args]
+
+commands:
+  rooms list                                 list rooms
+  rooms create [-moderated] <theme>          create a room and print its moderator token
+  rooms get <room_id>                        show a room with its pinned questions
+  rooms clone [-theme t] [-carry-over] <room_id>
+                                             clone a room (moderator), optionally with unanswered questions
+  rooms import <file|->                      create a room from a JSON export
+  messages list <room_id>                    list the messages of a room
+  messages post [-as source] <room_id> <text>
+                                             post a question, attributed to a source with -api-key
+  messages answer <room_id> <message_id>     mark a question as answered (moderator)
+  messages status <room_id> <message_id> <status>
+                                             set the answer status (moderator): open, answering,
+                                             answered, dismissed or duplicate
+  messages react <room_id> <message_id>      react to a question
+  messages pin|unpin <room_id> <message_id>  pin or unpin a question (moderator)
+  messages now-answering <room_id> [message_id]
+                                             highlight the question being answered, or clear it (moderator)
+  participant                                issue a participant identity and print its token
+  tail <room_id>                             follow the live events of a room
+  export [-format json|csv|md] [-o file] <room_id>
+                                             export a room and its messages
+
+flags:
+`
+
+// cli guarda as opções globais e o cliente da API.
+type cli struct {
+	client *client.Client
+	stdout io.Writer
+	color  bool
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "wsrsctl:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("wsrsctl", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	server := fs.String("server", envOr("WSRS_SERVER", "http://localhost:8080"), "server URL (env WSRS_SERVER)")
+	participant := fs.String("participant-token", os.Getenv("WSRS_PARTICIPANT_TOKEN"), "participant token issued by the participant command (env WSRS_PARTICIPANT_TOKEN)")
+	token := fs.String("token", os.Getenv("WSRS_MODERATOR_TOKEN"), "moderator token (env WSRS_MODERATOR_TOKEN)")
+	apiKey := fs.String("api-key", os.Getenv("WSRS_API_KEY"), "room API key for bots (env WSRS_API_KEY)")
+	noColor := fs.Bool("no-color", os.Getenv("NO_COLOR") != "", "disable colored output (env NO_COLOR)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	c, err := client.New(*server, client.WithParticipantToken(*participant), client.WithModeratorToken(*token), client.WithAPIKey(*apiKey))
+	if err != nil {
+		return err
+	}
+	app := cli{client: c, stdout: os.Stdout, color: !*noColor && isTerminal(os.Stdout)}
+
+	// Ctrl+C interrompe o comando em andamento, inclusive o tail
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+	switch cmd {
+	case "rooms":
+		return app.rooms(ctx, rest)
+	case "messages":
+		return app.messages(ctx, rest)
+	case "participant":
+		return app.participant(ctx)
+	case "tail":
+		return app.tail(ctx, rest)
+	case "export":
+		return app.export(ctx, rest)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// isTerminal indica se f é um terminal, para que as cores não poluam a saída redirecionada.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -14,16 +16,50 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/config"
+	"github.com/joao-ressel/go-server/internal/filter"
+	"github.com/joao-ressel/go-server/internal/metrics"
 	"github.com/joao-ressel/go-server/internal/store/pgstore"
+	"github.com/joao-ressel/go-server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // apiHandler é uma estrutura que lida com as requisições da API e gerencia WebSockets.
 type apiHandler struct {
-	q           *pgstore.Queries                                  // Consulta ao banco de dados
-	r           *chi.Mux                                          // Roteador de rotas
-	upgrader    websocket.Upgrader                                // Upgrader para WebSocket
-	subscribers map[string]map[*websocket.Conn]context.CancelFunc // Mapeia conexões WebSocket por sala
-	mu          *sync.Mutex                                       // Mutex para sincronização de acesso a subscribers
+	q                pgstore.Querier                            // Consulta ao banco de dados
+	r                *chi.Mux                                   // Roteador de rotas
+	upgrader         websocket.Upgrader                         // Upgrader para WebSocket
+	subscribers      map[string]map[*websocket.Conn]*subscriber // Mapeia conexões WebSocket por sala
+	mu               *sync.Mutex                                // Mutex para sincronização de acesso a subscribers
+	limiter          *rateLimiter                               // Limitador de requisições das rotas de escrita
+	filter           filter.Filter                              // Filtro de conteúdo aplicado às novas mensagens
+	reportThreshold  int                                        // Número de denúncias que oculta uma mensagem automaticamente
+	db               DB                                         // Conexão usada pelas verificações de prontidão
+	readinessTimeout time.Duration                              // Prazo de cada verificação de prontidão
+	draining         *atomic.Bool                               // Indica que o servidor está encerrando
+	origins          config.Origins                             // Origens aceitas pelo CORS e pelo upgrade de WebSocket
+	events           *eventLog                                  // Eventos recentes de cada sala, para o long polling
+	participantKey   []byte                                     // Chave que assina os tokens de participante
+}
+
+// subscriber guarda o estado de uma conexão WebSocket inscrita em uma sala.
+type subscriber struct {
+	cancel        context.CancelFunc // Encerra a inscrição
+	moderator     bool               // Indica se o cliente se autenticou como moderador da sala
+	participantID string             // Identificador do participante, se informado
+	ip            string             // IP do cliente
+	version       int                // Versão do envelope das mensagens negociada na inscrição
+}
+
+// Option configura aspectos opcionais do apiHandler criado por NewHandler.
+type Option func(*apiHandler)
+
+// WithRateLimit substitui a configuração padrão de limites de requisições (config.DefaultRateLimitConfig).
+func WithRateLimit(cfg config.RateLimitConfig) Option {
+	return func(h *apiHandler) {
+		h.limiter = newRateLimiter(cfg)
+	}
 }
 
 // ServeHTTP implementa a interface http.Handler para apiHandler.
@@ -32,64 +68,157 @@ func (h apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // NewHandler cria uma nova instância de apiHandler e configura as rotas.
-func NewHandler(q *pgstore.Queries) http.Handler {
+func NewHandler(q pgstore.Querier, opts ...Option) Handler {
 	a := apiHandler{
-		q:           q,
-		upgrader:    websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
-		subscribers: make(map[string]map[*websocket.Conn]context.CancelFunc),
-		mu:          &sync.Mutex{},
+		q:                q,
+		subscribers:      make(map[string]map[*websocket.Conn]*subscriber),
+		mu:               &sync.Mutex{},
+		limiter:          newRateLimiter(config.DefaultRateLimitConfig),
+		filter:           filter.New(q, nil),
+		reportThreshold:  config.DefaultReportThreshold,
+		readinessTimeout: config.DefaultReadinessTimeout,
+		draining:         &atomic.Bool{},
+		events:           newEventLog(),
 	}
 
+	for _, opt := range opts {
+		opt(&a)
+	}
+	if a.participantKey == nil {
+		a.participantKey = newParticipantKey()
+	}
+
+	a.upgrader = websocket.Upgrader{CheckOrigin: a.checkWebSocketOrigin}
+
 	r := chi.NewRouter()
-	r.Use(middleware.RequestID, middleware.Recoverer, middleware.Logger) // Middleware para request ID, recuperação de panics e logging
+	r.Use(middleware.RequestID, middleware.Recoverer, redactModeratorToken, middleware.Logger) // Middleware para request ID, recuperação de panics e logging
+	r.Use(metrics.Middleware, tracing.Middleware)                                              // Middleware para métricas e spans das requisições HTTP
 
 	// Configuração do CORS
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"}, // Permite todas as origens
+		AllowOriginFunc:  a.allowCORSOrigin,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", participantTokenHeader, apiKeyHeader, "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"Link", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "ETag"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
+	// Rota para métricas do Prometheus
+	r.Method(http.MethodGet, "/metrics", metrics.Handler())
+
+	// Rotas para verificações de vida e prontidão
+	r.Get("/healthz", a.handleHealthz)
+	r.Get("/readyz", a.handleReadyz)
+
 	// Rotas para WebSocket
 	r.Get("/subscribe/{room_id}", a.handleSubscribe)
 
 	// Rotas para a API principal
+	// As versões compartilham as rotas e os handlers, que consultam apiVersion para escolher o formato da resposta.
+	// /api/v1 mantém o formato congelado da v1 e /api é um alias da v1 para os clientes existentes.
+	// Códigos de status e autorização são comuns às versões; as mudanças incompatíveis na v1 estão no CHANGELOG.md.
 	r.Route("/api", func(r chi.Router) {
-		r.Route("/rooms", func(r chi.Router) {
-			r.Post("/", a.handleCreateRoom) // Criar nova sala
-			r.Get("/", a.handleGetRooms)    // Listar salas
-
-			r.Route("/{room_id}", func(r chi.Router) {
-				r.Get("/", a.handleGetRoom) // Obter detalhes de uma sala
-
-				r.Route("/messages", func(r chi.Router) {
-					r.Post("/", a.handleCreateRoomMessage) // Criar mensagem na sala
-					r.Get("/", a.handleGetRoomMessages)    // Listar mensagens da sala
-
-					r.Route("/{message_id}", func(r chi.Router) {
-						r.Get("/", a.handleGetRoomMessage)                 // Obter detalhes de uma mensagem
-						r.Patch("/react", a.handleReactToMessage)          // Reagir a mensagem
-						r.Delete("/react", a.handleRemoveReactFromMessage) // Remover reação de mensagem
-						r.Patch("/answer", a.handleMarkMessageAsAnswered)  // Marcar mensagem como respondida
-					})
-				})
-			})
+		r.Get("/openapi.json", a.handleGetOpenAPI) // Especificação OpenAPI da API
+
+		r.With(withAPIVersion(APIVersion1)).Route("/v1", a.routes)
+		r.With(withAPIVersion(APIVersion2)).Route("/v2", a.routes)
+		r.Group(func(r chi.Router) {
+			r.Use(withAPIVersion(APIVersion1))
+			a.routes(r)
 		})
 	})
 
+	checkOpenAPI(r) // Avisa sobre rotas que não constam na especificação
+
 	a.r = r
 	return a
 }
 
+// routes registra as rotas da API, atendidas por todas as versões.
+func (h apiHandler) routes(r chi.Router) {
+	r.With(h.rateLimit).Post("/participants", h.handleCreateParticipant) // Emitir identidade de participante
+
+	r.Route("/rooms", func(r chi.Router) {
+		r.With(h.rateLimit).Post("/", h.handleCreateRoom) // Criar nova sala
+		r.Get("/", h.handleGetRooms)                      // Listar salas
+
+		r.With(h.rateLimit).Post("/import", h.handleImportRoom) // Criar sala a partir de uma exportação
+
+		r.Route("/{room_id}", func(r chi.Router) {
+			r.Get("/", h.handleGetRoom)                                        // Obter detalhes de uma sala
+			r.Get("/poll", h.handlePollRoom)                                   // Aguardar eventos da sala (long polling)
+			r.With(h.rateLimit).Patch("/settings", h.handleUpdateRoomSettings) // Alterar configurações da sala (moderador)
+
+			r.With(h.rateLimit).Put("/now-answering", h.handleSetNowAnswering)      // Definir a mensagem sendo respondida (moderador)
+			r.With(h.rateLimit).Delete("/now-answering", h.handleClearNowAnswering) // Limpar a mensagem sendo respondida (moderador)
+
+			r.Get("/reports", h.handleGetRoomReports)    // Listar mensagens denunciadas (moderador)
+			r.Get("/export", h.handleExportRoom)         // Exportar a sala e as suas mensagens (JSON, CSV ou Markdown)
+			r.Get("/feed.atom", h.handleGetRoomAtomFeed) // Feed Atom das perguntas aprovadas
+			r.Get("/feed.rss", h.handleGetRoomRSSFeed)   // Feed RSS das perguntas aprovadas
+
+			r.With(h.rateLimit).Post("/clone", h.handleCloneRoom) // Clonar a sala, opcionalmente com as perguntas não respondidas (moderador)
+
+			r.Route("/bans", func(r chi.Router) {
+				r.Get("/", h.handleGetRoomBans)                                // Listar banimentos e silenciamentos (moderador)
+				r.With(h.rateLimit).Post("/", h.handleCreateRoomBan)           // Banir ou silenciar participante (moderador)
+				r.With(h.rateLimit).Delete("/{ban_id}", h.handleDeleteRoomBan) // Remover restrição (moderador)
+			})
+
+			r.Route("/filters", func(r chi.Router) {
+				r.Get("/", h.handleGetRoomFilterRules)                                 // Listar regras do filtro de conteúdo (moderador)
+				r.With(h.rateLimit).Post("/", h.handleCreateRoomFilterRule)            // Adicionar regra ao filtro (moderador)
+				r.With(h.rateLimit).Delete("/{rule_id}", h.handleDeleteRoomFilterRule) // Remover regra do filtro (moderador)
+			})
+
+			r.Route("/api-keys", func(r chi.Router) {
+				r.Get("/", h.handleGetRoomAPIKeys)                                // Listar chaves de API de bots (moderador)
+				r.With(h.rateLimit).Post("/", h.handleCreateRoomAPIKey)           // Criar chave de API (moderador)
+				r.With(h.rateLimit).Delete("/{key_id}", h.handleDeleteRoomAPIKey) // Revogar chave de API (moderador)
+			})
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Get("/", h.handleGetRoomWebhooks)                                    // Listar webhooks (moderador)
+				r.With(h.rateLimit).Post("/", h.handleCreateRoomWebhook)               // Cadastrar webhook (moderador)
+				r.With(h.rateLimit).Delete("/{webhook_id}", h.handleDeleteRoomWebhook) // Remover webhook (moderador)
+				r.Get("/deliveries", h.handleGetRoomWebhookDeliveries)                 // Registro de entregas dos webhooks (moderador)
+			})
+
+			r.Route("/messages", func(r chi.Router) {
+				r.With(h.rateLimit).Post("/", h.handleCreateRoomMessage) // Criar mensagem na sala
+				r.Get("/", h.handleGetRoomMessages)                      // Listar mensagens da sala
+
+				r.Route("/{message_id}", func(r chi.Router) {
+					r.Get("/", h.handleGetRoomMessage)                                   // Obter detalhes de uma mensagem
+					r.With(h.rateLimit).Patch("/react", h.handleReactToMessage)          // Reagir a mensagem
+					r.With(h.rateLimit).Delete("/react", h.handleRemoveReactFromMessage) // Remover reação de mensagem
+					r.With(h.rateLimit).Patch("/answer", h.handleMarkMessageAsAnswered)  // Marcar mensagem como respondida
+					r.With(h.rateLimit).Patch("/status", h.handleUpdateMessageStatus)    // Alterar estado de resposta (moderador)
+					r.With(h.rateLimit).Patch("/approve", h.handleApproveMessage)        // Aprovar mensagem pendente (moderador)
+					r.With(h.rateLimit).Patch("/reject", h.handleRejectMessage)          // Rejeitar mensagem pendente (moderador)
+					r.With(h.rateLimit).Patch("/pin", h.handlePinMessage)                // Fixar mensagem (moderador)
+					r.With(h.rateLimit).Delete("/pin", h.handleUnpinMessage)             // Desafixar mensagem (moderador)
+					r.With(h.rateLimit).Post("/report", h.handleReportMessage)           // Denunciar mensagem
+				})
+			})
+		})
+	})
+}
+
 // Constantes para os tipos de mensagens enviadas aos clientes via WebSocket
 const (
 	MessageKindMessageCreated          = "message_created"
 	MessageKindMessageRactionIncreased = "message_reaction_increased"
 	MessageKindMessageRactionDecreased = "message_reaction_decreased"
-	MessageKindMessageAnswered         = "message_answered"
+	MessageKindMessageAnswered         = "message_answered" // Apenas para a v1; as versões seguintes recebem message_status_changed
+	MessageKindMessageStatusChanged    = "message_status_changed"
+	MessageKindMessagePending          = "message_pending"  // Enviada apenas aos moderadores
+	MessageKindMessageRejected         = "message_rejected" // Enviada apenas aos moderadores
+	MessageKindMessageHidden           = "message_hidden"
+	MessageKindMessagePinned           = "message_pinned"
+	MessageKindNowAnswering            = "now_answering"
+	MessageKindServerShuttingDown      = "server_shutting_down"
 )
 
 // Estruturas para diferentes tipos de mensagens
@@ -107,43 +236,130 @@ type MessageMessageAnswered struct {
 	ID string `json:"id"`
 }
 
+type MessageMessageStatusChanged struct {
+	ID        string `json:"id"`
+	OldStatus string `json:"old_status"`
+	NewStatus string `json:"new_status"`
+}
+
 type MessageMessageCreated struct {
-	ID      string `json:"id"`
-	Message string `json:"message"`
+	ID          string `json:"id"`
+	Message     string `json:"message"`
+	Attribution string `json:"attribution,omitempty"` // Bot ou origem da mensagem, quando enviada com uma chave de API
+}
+
+type MessageMessagePending struct {
+	ID          string `json:"id"`
+	Message     string `json:"message"`
+	Attribution string `json:"attribution,omitempty"`
+}
+
+type MessageMessageRejected struct {
+	ID string `json:"id"`
+}
+
+type MessageMessageHidden struct {
+	ID string `json:"id"`
+}
+
+type MessageMessagePinned struct {
+	ID     string `json:"id"`
+	Pinned bool   `json:"pinned"` // Falso quando a mensagem foi desafixada
+}
+
+type MessageNowAnswering struct {
+	ID      string `json:"id"`      // Vazio quando nenhuma mensagem está sendo respondida
+	Message string `json:"message"` // Texto da mensagem, para que ela seja exibida sem outra consulta
+}
+
+type MessageServerShuttingDown struct {
+	Reason string `json:"reason"`
 }
 
 type Message struct {
-	Kind   string `json:"kind"`
-	Value  any    `json:"value"`
-	RoomID string `json:"-"`
+	Version        int    `json:"version"` // Versão do envelope negociada pelo assinante na inscrição
+	Kind           string `json:"kind"`
+	Value          any    `json:"value"`
+	RoomID         string `json:"-"`
+	ModeratorsOnly bool   `json:"-"` // Se verdadeiro, a mensagem é enviada apenas aos moderadores da sala
 }
 
 // notifyClients envia uma mensagem para todos os clientes assinantes da sala especificada.
-func (h apiHandler) notifyClients(msg Message) {
+// O contexto é usado apenas para vincular o span do broadcast ao trace da requisição que o originou.
+// As entregas aos webhooks não são feitas aqui: elas são gravadas com enqueueWebhooks na transação que produziu o evento.
+func (h apiHandler) notifyClients(ctx context.Context, msg Message) {
+	ctx, span := tracing.Tracer.Start(tracing.Detach(ctx), "broadcast "+msg.Kind, trace.WithAttributes(
+		attribute.String("wsrs.room_id", msg.RoomID),
+		attribute.String("wsrs.message_kind", msg.Kind),
+	))
+	defer span.End()
+
+	// Os clientes de long polling recebem o evento mesmo que a sala não tenha assinantes conectados
+	h.events.append(msg)
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	subscribers, ok := h.subscribers[msg.RoomID]
+	span.SetAttributes(attribute.Int("wsrs.subscribers", len(subscribers)))
 	if !ok || len(subscribers) == 0 {
 		return // Se não houver assinantes para a sala, retorna
 	}
 
-	for conn, cancel := range subscribers {
-		if err := conn.WriteJSON(msg); err != nil {
+	start := time.Now()
+	defer func() {
+		metrics.BroadcastDuration.WithLabelValues(msg.Kind).Observe(time.Since(start).Seconds())
+	}()
+
+	for conn, sub := range subscribers {
+		if msg.ModeratorsOnly && !sub.moderator {
+			continue // Eventos de moderação não são enviados à audiência
+		}
+
+		event, ok := eventForVersion(msg, sub.version)
+		if !ok {
+			continue // O evento não existe na versão negociada pelo assinante
+		}
+		if err := conn.WriteJSON(event); err != nil {
 			slog.Error("failed to send message to client", "error", err)
-			cancel() // Cancela a conexão se ocorrer um erro
+			metrics.BroadcastFailedWrites.WithLabelValues(msg.Kind).Inc()
+			span.AddEvent("write failed", trace.WithAttributes(attribute.String("error", err.Error())))
+			sub.cancel() // Cancela a conexão se ocorrer um erro
+			continue
 		}
+		metrics.BroadcastMessagesSent.WithLabelValues(msg.Kind).Inc()
 	}
 }
 
 // handleSubscribe lida com conexões WebSocket para uma sala específica.
 func (h apiHandler) handleSubscribe(w http.ResponseWriter, r *http.Request) {
-	_, rawRoomID, _, ok := h.readRoom(w, r) // Obtém o ID da sala a partir da requisição
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala a partir da requisição
+	if !ok {
+		return
+	}
+
+	if h.draining.Load() { // Durante o encerramento, novas inscrições são recusadas
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	if !h.checkBan(w, r, roomID, BanKindBan) { // Participantes banidos não podem acompanhar a sala
+		return
+	}
+
+	version, subprotocol, ok := negotiateWebSocketVersion(r) // Versão do envelope das mensagens enviadas ao cliente
 	if !ok {
+		http.Error(w, "unsupported version", http.StatusBadRequest)
 		return
 	}
+	var responseHeader http.Header
+	if subprotocol != "" {
+		responseHeader = http.Header{"Sec-WebSocket-Protocol": {subprotocol}}
+	}
 
-	c, err := h.upgrader.Upgrade(w, r, nil) // Faz o upgrade da conexão para WebSocket
+	moderator := h.isModerator(r, roomID) // Moderadores recebem também os eventos da fila de moderação
+
+	c, err := h.upgrader.Upgrade(w, r, responseHeader) // Faz o upgrade da conexão para WebSocket
 	if err != nil {
 		slog.Warn("failed to upgrade connection", "error", err)
 		http.Error(w, "failed to upgrade to ws connection", http.StatusBadRequest)
@@ -155,24 +371,44 @@ func (h apiHandler) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithCancel(r.Context())
 
 	h.mu.Lock()
+	if h.draining.Load() { // O encerramento pode ter começado durante o upgrade
+		h.mu.Unlock()
+		cancel()
+		_ = c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down"), time.Now().Add(time.Second))
+		return
+	}
 	if _, ok := h.subscribers[rawRoomID]; !ok {
-		h.subscribers[rawRoomID] = make(map[*websocket.Conn]context.CancelFunc)
+		h.subscribers[rawRoomID] = make(map[*websocket.Conn]*subscriber)
 	}
-	slog.Info("new client connected", "room_id", rawRoomID, "client_ip", r.RemoteAddr)
-	h.subscribers[rawRoomID][c] = cancel
+	slog.Info("new client connected", "room_id", rawRoomID, "client_ip", r.RemoteAddr, "moderator", moderator)
+	h.subscribers[rawRoomID][c] = &subscriber{
+		cancel:        cancel,
+		moderator:     moderator,
+		participantID: h.participantID(r),
+		ip:            clientIP(r),
+		version:       version,
+	}
+	metrics.WebSocketConnections.WithLabelValues(rawRoomID).Set(float64(len(h.subscribers[rawRoomID])))
 	h.mu.Unlock()
 
 	<-ctx.Done() // Aguarda até que o contexto seja cancelado
 
 	h.mu.Lock()
 	delete(h.subscribers[rawRoomID], c) // Remove o cliente da lista de assinantes quando o contexto for cancelado
+	if len(h.subscribers[rawRoomID]) == 0 {
+		delete(h.subscribers, rawRoomID)
+		metrics.WebSocketConnections.DeleteLabelValues(rawRoomID) // Evita manter séries de salas sem assinantes
+	} else {
+		metrics.WebSocketConnections.WithLabelValues(rawRoomID).Set(float64(len(h.subscribers[rawRoomID])))
+	}
 	h.mu.Unlock()
 }
 
 // handleCreateRoom cria uma nova sala com base no corpo da requisição.
 func (h apiHandler) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
 	type _body struct {
-		Theme string `json:"theme"`
+		Theme     string `json:"theme"`
+		Moderated bool   `json:"moderated"`
 	}
 	var body _body
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -180,18 +416,28 @@ func (h apiHandler) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roomID, err := h.q.InsertRoom(r.Context(), body.Theme) // Insere a sala no banco de dados
+	// A sala e o seu token de moderador são gravados juntos, para que não exista sala sem moderador
+	var (
+		roomID uuid.UUID
+		token  string
+	)
+	err := h.inOptionalTx(r.Context(), func(q pgstore.Querier) error {
+		var err error
+		roomID, token, err = createRoom(r.Context(), q, pgstore.InsertRoomParams{Theme: body.Theme, Moderated: body.Moderated})
+		return err
+	})
 	if err != nil {
-		slog.Error("failed to insert room", "error", err)
+		slog.Error("failed to create room", "error", err)
 		http.Error(w, "something went wrong", http.StatusInternalServerError)
 		return
 	}
 
 	type response struct {
-		ID string `json:"id"`
+		ID             string `json:"id"`
+		ModeratorToken string `json:"moderator_token"`
 	}
 
-	sendJSON(w, response{ID: roomID.String()}) // Envia o ID da nova sala como resposta
+	sendJSON(w, response{ID: roomID.String(), ModeratorToken: token}) // Envia o ID da nova sala e o token de moderador como resposta
 }
 
 // handleGetRooms lista todas as salas existentes.
@@ -207,28 +453,67 @@ func (h apiHandler) handleGetRooms(w http.ResponseWriter, r *http.Request) {
 		rooms = []pgstore.Room{}
 	}
 
-	sendJSON(w, rooms) // Envia a lista de salas como resposta
+	sendJSON(w, roomsForVersion(apiVersion(r.Context()), rooms)) // Envia a lista de salas como resposta
 }
 
-// handleGetRoom obtém os detalhes de uma sala específica.
+// handleGetRoom obtém os detalhes de uma sala específica, com a mensagem sendo respondida e as mensagens fixadas.
 func (h apiHandler) handleGetRoom(w http.ResponseWriter, r *http.Request) {
-	room, _, _, ok := h.readRoom(w, r) // Obtém os detalhes da sala
+	room, _, roomID, ok := h.readRoom(w, r) // Obtém os detalhes da sala
 	if !ok {
 		return
 	}
 
-	sendJSON(w, room) // Envia os detalhes da sala como resposta
+	// A versão da sala muda também com as suas mensagens, inclusive as em destaque
+	if notModified(w, r, versionETag(r.Context(), room.Version, "")) {
+		return
+	}
+
+	// A v1 não inclui as mensagens em destaque
+	if apiVersion(r.Context()) == APIVersion1 {
+		sendJSON(w, newRoomV1(room))
+		return
+	}
+
+	highlighted, err := h.q.GetRoomHighlightedMessages(r.Context(), roomID)
+	if err != nil {
+		slog.Error("failed to get highlighted messages", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	type response struct {
+		pgstore.Room
+		NowAnswering   *pgstore.Message  `json:"now_answering"`   // Mensagem sendo respondida, se houver
+		PinnedMessages []pgstore.Message `json:"pinned_messages"` // Mensagens fixadas, da mais antiga para a mais recente
+	}
+
+	res := response{Room: room, PinnedMessages: []pgstore.Message{}}
+	for _, m := range highlighted {
+		if room.NowAnsweringID.Valid && m.ID == uuid.UUID(room.NowAnsweringID.Bytes) {
+			res.NowAnswering = &m
+		}
+		if m.Pinned {
+			res.PinnedMessages = append(res.PinnedMessages, m)
+		}
+	}
+
+	sendJSON(w, res) // Envia os detalhes da sala, com as mensagens em destaque, como resposta
 }
 
 // handleCreateRoomMessage cria uma nova mensagem em uma sala.
 func (h apiHandler) handleCreateRoomMessage(w http.ResponseWriter, r *http.Request) {
-	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	room, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
 	if !ok {
 		return
 	}
 
+	if !h.checkBan(w, r, roomID, BanKindBan, BanKindMute) { // Participantes banidos ou silenciados não podem enviar mensagens
+		return
+	}
+
 	type _body struct {
-		Message string `json:"message"`
+		Message     string `json:"message"`
+		Attribution string `json:"attribution"` // Bot ou origem da mensagem; exige uma chave de API
 	}
 	var body _body
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -236,7 +521,78 @@ func (h apiHandler) handleCreateRoomMessage(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	messageID, err := h.q.InsertMessage(r.Context(), pgstore.InsertMessageParams{RoomID: roomID, Message: body.Message}) // Insere a mensagem no banco de dados
+	// Bots enviam mensagens com uma chave de API da sala e podem atribuí-las a uma origem
+	key, hasKey, ok := h.roomAPIKey(w, r, roomID)
+	if !ok {
+		return
+	}
+	attribution, ok := messageAttribution(w, body.Attribution, key, hasKey)
+	if !ok {
+		return
+	}
+
+	// Em salas moderadas, mensagens da audiência aguardam aprovação antes de serem exibidas
+	moderator := h.isModerator(r, roomID)
+	status := ModerationStatusApproved
+	if room.Moderated && !moderator {
+		status = ModerationStatusPending
+	}
+
+	// Mensagens da audiência passam pelo filtro de conteúdo antes de serem gravadas
+	if !moderator {
+		result, err := h.filter.Check(r.Context(), roomID, body.Message)
+		if err != nil {
+			slog.Error("failed to check message content", "error", err)
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
+			return
+		}
+
+		switch result.Action {
+		case filter.ActionReject:
+			http.Error(w, "message rejected by content filter", http.StatusUnprocessableEntity)
+			return
+		case filter.ActionModerate:
+			status = ModerationStatusPending
+		}
+		body.Message = result.Text
+	}
+
+	var (
+		messageID uuid.UUID
+		event     Message
+	)
+	err := h.inRoomTx(r.Context(), roomID, func(q pgstore.Querier) (err error) {
+		messageID, err = q.InsertMessage(r.Context(), pgstore.InsertMessageParams{RoomID: roomID, Message: body.Message, ModerationStatus: status, Attribution: attribution}) // Insere a mensagem no banco de dados
+		if err != nil {
+			return err
+		}
+
+		if status == ModerationStatusPending {
+			// Notifica apenas os moderadores sobre a mensagem aguardando aprovação
+			event = Message{
+				Kind:           MessageKindMessagePending,
+				RoomID:         rawRoomID,
+				ModeratorsOnly: true,
+				Value: MessageMessagePending{
+					ID:          messageID.String(),
+					Message:     body.Message,
+					Attribution: attribution,
+				},
+			}
+		} else {
+			// Notifica os clientes assinantes da sala sobre a nova mensagem
+			event = Message{
+				Kind:   MessageKindMessageCreated,
+				RoomID: rawRoomID,
+				Value: MessageMessageCreated{
+					ID:          messageID.String(),
+					Message:     body.Message,
+					Attribution: attribution,
+				},
+			}
+		}
+		return enqueueWebhooks(r.Context(), q, event)
+	})
 	if err != nil {
 		slog.Error("failed to insert message", "error", err)
 		http.Error(w, "something went wrong", http.StatusInternalServerError)
@@ -244,30 +600,45 @@ func (h apiHandler) handleCreateRoomMessage(w http.ResponseWriter, r *http.Reque
 	}
 
 	type response struct {
-		ID string `json:"id"`
+		ID               string `json:"id"`
+		ModerationStatus string `json:"moderation_status"`
 	}
 
-	sendJSON(w, response{ID: messageID.String()}) // Envia o ID da nova mensagem como resposta
+	sendJSON(w, response{ID: messageID.String(), ModerationStatus: status}) // Envia o ID da nova mensagem como resposta
+
+	go h.notifyClients(r.Context(), event)
+}
 
-	// Notifica os clientes assinantes da sala sobre a nova mensagem
-	go h.notifyClients(Message{
-		Kind:   MessageKindMessageCreated,
-		RoomID: rawRoomID,
-		Value: MessageMessageCreated{
-			ID:      messageID.String(),
-			Message: body.Message,
-		},
+// visibleRoomMessages obtém as mensagens da sala visíveis para o cliente da requisição.
+// Moderadores veem todas as mensagens; a audiência vê apenas as aprovadas.
+func (h apiHandler) visibleRoomMessages(r *http.Request, roomID uuid.UUID) ([]pgstore.Message, error) {
+	if h.isModerator(r, roomID) {
+		return h.q.GetRoomMessages(r.Context(), roomID)
+	}
+	return h.q.GetRoomMessagesByModerationStatus(r.Context(), pgstore.GetRoomMessagesByModerationStatusParams{
+		RoomID:           roomID,
+		ModerationStatus: ModerationStatusApproved,
 	})
 }
 
 // handleGetRoomMessages lista todas as mensagens de uma sala específica.
+// A ETag da lista é a versão da sala, que muda a cada alteração das suas mensagens.
 func (h apiHandler) handleGetRoomMessages(w http.ResponseWriter, r *http.Request) {
-	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	room, _, roomID, ok := h.readRoom(w, r) // Obtém a sala
 	if !ok {
 		return
 	}
 
-	messages, err := h.q.GetRoomMessages(r.Context(), roomID) // Obtém as mensagens da sala
+	// A versão é lida antes das mensagens: a lista enviada nunca é mais antiga do que a sua ETag
+	variant := ""
+	if h.isModerator(r, roomID) {
+		variant = "moderator" // Os moderadores recebem também as mensagens pendentes e rejeitadas
+	}
+	if notModified(w, r, versionETag(r.Context(), room.Version, variant)) {
+		return
+	}
+
+	messages, err := h.visibleRoomMessages(r, roomID) // Obtém as mensagens visíveis para o cliente
 	if err != nil {
 		http.Error(w, "something went wrong", http.StatusInternalServerError)
 		slog.Error("failed to get room messages", "error", err)
@@ -278,12 +649,12 @@ func (h apiHandler) handleGetRoomMessages(w http.ResponseWriter, r *http.Request
 		messages = []pgstore.Message{}
 	}
 
-	sendJSON(w, messages) // Envia a lista de mensagens como resposta
+	sendJSON(w, messagesForVersion(apiVersion(r.Context()), messages)) // Envia a lista de mensagens como resposta
 }
 
 // handleGetRoomMessage obtém os detalhes de uma mensagem específica.
 func (h apiHandler) handleGetRoomMessage(w http.ResponseWriter, r *http.Request) {
-	_, _, _, ok := h.readRoom(w, r) // Obtém o ID da sala
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
 	if !ok {
 		return
 	}
@@ -298,7 +669,7 @@ func (h apiHandler) handleGetRoomMessage(w http.ResponseWriter, r *http.Request)
 	messages, err := h.q.GetMessage(r.Context(), messageID) // Obtém os detalhes da mensagem
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			http.Error(w, "message not found", http.StatusBadRequest)
+			http.Error(w, "message not found", http.StatusNotFound)
 			return
 		}
 
@@ -307,16 +678,36 @@ func (h apiHandler) handleGetRoomMessage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	sendJSON(w, messages) // Envia os detalhes da mensagem como resposta
+	// Mensagens de outra sala não são expostas, nem mesmo aos moderadores desta
+	if messages.RoomID != roomID {
+		http.Error(w, "message not found", http.StatusNotFound)
+		return
+	}
+
+	// Mensagens pendentes ou rejeitadas são visíveis apenas aos moderadores
+	if messages.ModerationStatus != ModerationStatusApproved && !h.isModerator(r, roomID) {
+		http.Error(w, "message not found", http.StatusNotFound)
+		return
+	}
+
+	if notModified(w, r, versionETag(r.Context(), messages.Version, "")) {
+		return
+	}
+
+	sendJSON(w, messageForVersion(apiVersion(r.Context()), messages)) // Envia os detalhes da mensagem como resposta
 }
 
 // handleReactToMessage adiciona uma reação a uma mensagem.
 func (h apiHandler) handleReactToMessage(w http.ResponseWriter, r *http.Request) {
-	_, rawRoomID, _, ok := h.readRoom(w, r) // Obtém o ID da sala
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
 	if !ok {
 		return
 	}
 
+	if !h.checkBan(w, r, roomID, BanKindBan, BanKindMute) { // Participantes banidos ou silenciados não podem reagir
+		return
+	}
+
 	rawID := chi.URLParam(r, "message_id") // Obtém o ID da mensagem da URL
 	id, err := uuid.Parse(rawID)
 	if err != nil {
@@ -324,8 +715,37 @@ func (h apiHandler) handleReactToMessage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	count, err := h.q.ReactToMessage(r.Context(), id) // Adiciona uma reação à mensagem
+	var (
+		count int64
+		event Message
+	)
+	err = h.ifMatch(r, roomID, lockMessage(id, roomID), func(q pgstore.Querier) (err error) {
+		// Somente mensagens aprovadas da sala recebem reações
+		count, err = q.ReactToMessage(r.Context(), pgstore.ReactToMessageParams{ID: id, RoomID: roomID}) // Adiciona uma reação à mensagem
+		if err != nil {
+			return err
+		}
+
+		event = Message{
+			Kind:   MessageKindMessageRactionIncreased,
+			RoomID: rawRoomID,
+			Value: MessageMessageReactionIncreased{
+				ID:    rawID,
+				Count: count,
+			},
+		}
+		return enqueueWebhooks(r.Context(), q, event)
+	})
 	if err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			http.Error(w, "message has changed", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "message not found", http.StatusNotFound)
+			return
+		}
+
 		http.Error(w, "something went wrong", http.StatusInternalServerError)
 		slog.Error("failed to react to message", "error", err)
 		return
@@ -338,23 +758,20 @@ func (h apiHandler) handleReactToMessage(w http.ResponseWriter, r *http.Request)
 	sendJSON(w, response{Count: count}) // Envia a contagem atualizada de reações como resposta
 
 	// Notifica os clientes assinantes da sala sobre a reação aumentada
-	go h.notifyClients(Message{
-		Kind:   MessageKindMessageRactionIncreased,
-		RoomID: rawRoomID,
-		Value: MessageMessageReactionIncreased{
-			ID:    rawID,
-			Count: count,
-		},
-	})
+	go h.notifyClients(r.Context(), event)
 }
 
 // handleRemoveReactFromMessage remove uma reação de uma mensagem.
 func (h apiHandler) handleRemoveReactFromMessage(w http.ResponseWriter, r *http.Request) {
-	_, rawRoomID, _, ok := h.readRoom(w, r) // Obtém o ID da sala
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
 	if !ok {
 		return
 	}
 
+	if !h.checkBan(w, r, roomID, BanKindBan, BanKindMute) { // Participantes banidos ou silenciados não podem reagir
+		return
+	}
+
 	rawID := chi.URLParam(r, "message_id") // Obtém o ID da mensagem da URL
 	id, err := uuid.Parse(rawID)
 	if err != nil {
@@ -362,8 +779,37 @@ func (h apiHandler) handleRemoveReactFromMessage(w http.ResponseWriter, r *http.
 		return
 	}
 
-	count, err := h.q.RemoveReactionFromMessage(r.Context(), id) // Remove uma reação da mensagem
+	var (
+		count int64
+		event Message
+	)
+	err = h.ifMatch(r, roomID, lockMessage(id, roomID), func(q pgstore.Querier) (err error) {
+		// Somente mensagens aprovadas da sala recebem reações
+		count, err = q.RemoveReactionFromMessage(r.Context(), pgstore.RemoveReactionFromMessageParams{ID: id, RoomID: roomID}) // Remove uma reação da mensagem
+		if err != nil {
+			return err
+		}
+
+		event = Message{
+			Kind:   MessageKindMessageRactionDecreased,
+			RoomID: rawRoomID,
+			Value: MessageMessageReactionDecreased{
+				ID:    rawID,
+				Count: count,
+			},
+		}
+		return enqueueWebhooks(r.Context(), q, event)
+	})
 	if err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			http.Error(w, "message has changed", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "message not found", http.StatusNotFound)
+			return
+		}
+
 		http.Error(w, "something went wrong", http.StatusInternalServerError)
 		slog.Error("failed to react to message", "error", err)
 		return
@@ -376,45 +822,24 @@ func (h apiHandler) handleRemoveReactFromMessage(w http.ResponseWriter, r *http.
 	sendJSON(w, response{Count: count}) // Envia a contagem atualizada de reações como resposta
 
 	// Notifica os clientes assinantes da sala sobre a reação diminuída
-	go h.notifyClients(Message{
-		Kind:   MessageKindMessageRactionDecreased,
-		RoomID: rawRoomID,
-		Value: MessageMessageReactionDecreased{
-			ID:    rawID,
-			Count: count,
-		},
-	})
+	go h.notifyClients(r.Context(), event)
 }
 
-// handleMarkMessageAsAnswered marca uma mensagem como respondida.
+// handleMarkMessageAsAnswered marca uma mensagem como respondida (moderador).
+// Equivale a alterar o estado para "answered" (PATCH /status), com a mesma exigência do token de moderador.
 func (h apiHandler) handleMarkMessageAsAnswered(w http.ResponseWriter, r *http.Request) {
-	_, rawRoomID, _, ok := h.readRoom(w, r) // Obtém o ID da sala
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
 	if !ok {
 		return
 	}
 
-	rawID := chi.URLParam(r, "message_id") // Obtém o ID da mensagem da URL
-	id, err := uuid.Parse(rawID)
-	if err != nil {
-		http.Error(w, "invalid message id", http.StatusBadRequest)
+	if !h.requireModerator(w, r, roomID) {
 		return
 	}
 
-	err = h.q.MarkMessageAsAnswered(r.Context(), id) // Marca a mensagem como respondida
-	if err != nil {
-		http.Error(w, "something went wrong", http.StatusInternalServerError)
-		slog.Error("failed to react to message", "error", err)
+	if _, ok := h.changeMessageStatus(w, r, rawRoomID, roomID, MessageStatusAnswered); !ok {
 		return
 	}
 
 	w.WriteHeader(http.StatusOK) // Envia status 200 OK
-
-	// Notifica os clientes assinantes da sala sobre a mensagem respondida
-	go h.notifyClients(Message{
-		Kind:   MessageKindMessageAnswered,
-		RoomID: rawRoomID,
-		Value: MessageMessageAnswered{
-			ID: rawID,
-		},
-	})
 }
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// apiKeyHeader é o cabeçalho em que bots e integrações apresentam a chave de API da sala.
+const apiKeyHeader = "X-Api-Key"
+
+// maxAttributionLength é o tamanho máximo do nome de uma chave e da atribuição de uma mensagem,
+// conforme as colunas room_api_keys.name e messages.attribution.
+const maxAttributionLength = 64
+
+// apiKeyKey é a chave de contexto da chave de API já autenticada pelo limitador de requisições.
+type apiKeyKey struct{}
+
+// roomAPIKey autentica a chave de API enviada na requisição.
+// Retorna found = false se nenhuma chave foi enviada; se a chave for inválida, responde com erro e retorna ok = false.
+func (h apiHandler) roomAPIKey(w http.ResponseWriter, r *http.Request, roomID uuid.UUID) (key pgstore.GetRoomApiKeyByHashRow, found, ok bool) {
+	raw := r.Header.Get(apiKeyHeader)
+	if raw == "" {
+		return key, false, true
+	}
+
+	key, cached := r.Context().Value(apiKeyKey{}).(pgstore.GetRoomApiKeyByHashRow)
+	if !cached || key.RoomID != roomID {
+		var err error
+		key, err = h.q.GetRoomApiKeyByHash(r.Context(), pgstore.GetRoomApiKeyByHashParams{RoomID: roomID, KeyHash: hashToken(raw)})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				http.Error(w, "invalid api key", http.StatusForbidden)
+				return key, true, false
+			}
+
+			slog.Error("failed to get api key", "error", err)
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
+			return key, true, false
+		}
+	}
+
+	if err := h.q.TouchRoomApiKey(r.Context(), key.ID); err != nil {
+		slog.Warn("failed to update api key usage", "error", err)
+	}
+
+	return key, true, true
+}
+
+// lookupAPIKey autentica a chave de API enviada para a sala rawRoomID, sem responder com erro.
+// É usada pelo limitador de requisições, que dá a cada chave um balde próprio; chaves inválidas são recusadas pelo handler.
+func (h apiHandler) lookupAPIKey(r *http.Request, rawRoomID string) (pgstore.GetRoomApiKeyByHashRow, bool) {
+	raw := r.Header.Get(apiKeyHeader)
+	if raw == "" {
+		return pgstore.GetRoomApiKeyByHashRow{}, false
+	}
+
+	roomID, err := uuid.Parse(rawRoomID)
+	if err != nil {
+		return pgstore.GetRoomApiKeyByHashRow{}, false
+	}
+
+	key, err := h.q.GetRoomApiKeyByHash(r.Context(), pgstore.GetRoomApiKeyByHashParams{RoomID: roomID, KeyHash: hashToken(raw)})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			slog.Warn("failed to get api key for rate limiting", "error", err)
+		}
+		return pgstore.GetRoomApiKeyByHashRow{}, false
+	}
+	return key, true
+}
+
+// messageAttribution define a atribuição de uma mensagem nova.
+// Apenas requisições autenticadas com uma chave de API podem informar uma atribuição; sem ela, é usado o nome da chave.
+func messageAttribution(w http.ResponseWriter, requested string, key pgstore.GetRoomApiKeyByHashRow, found bool) (string, bool) {
+	requested = strings.TrimSpace(requested)
+	if !found {
+		if requested != "" {
+			http.Error(w, "attribution requires an api key", http.StatusUnauthorized)
+			return "", false
+		}
+		return "", true
+	}
+
+	if requested == "" {
+		return key.Name, true
+	}
+	if utf8.RuneCountInString(requested) > maxAttributionLength {
+		http.Error(w, "attribution is too long", http.StatusBadRequest)
+		return "", false
+	}
+	return requested, true
+}
+
+// handleGetRoomAPIKeys lista as chaves de API de uma sala, sem as chaves em si.
+func (h apiHandler) handleGetRoomAPIKeys(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	keys, err := h.q.GetRoomApiKeys(r.Context(), roomID)
+	if err != nil {
+		slog.Error("failed to get room api keys", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if keys == nil {
+		keys = []pgstore.GetRoomApiKeysRow{}
+	}
+
+	sendJSON(w, keys) // Envia a lista de chaves como resposta
+}
+
+// handleCreateRoomAPIKey cria uma chave de API para que um bot envie mensagens à sala.
+// Apenas o hash da chave é armazenado; a chave só é devolvida nesta resposta.
+func (h apiHandler) handleCreateRoomAPIKey(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		Name string `json:"name"` // Nome do bot ou da origem, usado como atribuição padrão
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if body.Name == "" || utf8.RuneCountInString(body.Name) > maxAttributionLength {
+		http.Error(w, "name must have between 1 and 64 characters", http.StatusBadRequest)
+		return
+	}
+
+	key, keyHash, err := newModeratorToken() // Mesmo formato e hash do token de moderador
+	if err != nil {
+		slog.Error("failed to generate api key", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	created, err := h.q.InsertRoomApiKey(r.Context(), pgstore.InsertRoomApiKeyParams{RoomID: roomID, Name: body.Name, KeyHash: keyHash})
+	if err != nil {
+		slog.Error("failed to insert room api key", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	type response struct {
+		pgstore.InsertRoomApiKeyRow
+		Key string `json:"key"`
+	}
+
+	sendJSON(w, response{InsertRoomApiKeyRow: created, Key: key}) // Envia a chave criada como resposta
+}
+
+// handleDeleteRoomAPIKey revoga uma chave de API de uma sala.
+func (h apiHandler) handleDeleteRoomAPIKey(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	keyID, err := uuid.Parse(chi.URLParam(r, "key_id")) // Obtém o ID da chave da URL
+	if err != nil {
+		http.Error(w, "invalid api key id", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.q.DeleteRoomApiKey(r.Context(), pgstore.DeleteRoomApiKeyParams{ID: keyID, RoomID: roomID})
+	if err != nil {
+		slog.Error("failed to delete room api key", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if deleted == 0 {
+		http.Error(w, "api key not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK) // Envia status 200 OK
+}
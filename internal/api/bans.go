@@ -0,0 +1,261 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Tipos de restrição aplicáveis a um participante
+const (
+	BanKindBan  = "ban"  // Impede enviar mensagens, reagir e acompanhar a sala
+	BanKindMute = "mute" // Impede enviar mensagens e reagir, mas permite acompanhar a sala
+)
+
+// parseIPRange normaliza um IP ou uma faixa CIDR para o formato de prefixo. Endereços IPv4 mapeados em IPv6
+// ("::ffff:10.0.0.1") viram endereços IPv4, como os IPs dos clientes comparados por banMatches.
+func parseIPRange(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if addr := prefix.Addr(); addr.Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// banMatches indica se a restrição se aplica ao participante e ao IP informados.
+// participant deve ser um ID verificado (veja participantID); um cliente sem token só é alcançado pelas restrições de IP.
+func banMatches(ban pgstore.RoomBan, participant, ip string) bool {
+	if ban.ParticipantID != "" && ban.ParticipantID == participant {
+		return true
+	}
+
+	if ban.IpRange == "" {
+		return false
+	}
+
+	prefix, err := netip.ParsePrefix(ban.IpRange)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return prefix.Contains(addr.Unmap())
+}
+
+// checkBan responde com erro e retorna false se o cliente possuir uma restrição em vigor de um dos tipos informados.
+func (h apiHandler) checkBan(w http.ResponseWriter, r *http.Request, roomID uuid.UUID, kinds ...string) bool {
+	bans, err := h.q.GetActiveRoomBans(r.Context(), roomID)
+	if err != nil {
+		slog.Error("failed to get room bans", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return false
+	}
+
+	participant, ip := h.participantID(r), clientIP(r)
+	for _, ban := range bans {
+		if !banMatches(ban, participant, ip) {
+			continue
+		}
+
+		for _, kind := range kinds {
+			if ban.Kind != kind {
+				continue
+			}
+
+			if kind == BanKindMute {
+				http.Error(w, "you are muted in this room", http.StatusForbidden)
+			} else {
+				http.Error(w, "you are banned from this room", http.StatusForbidden)
+			}
+			return false
+		}
+	}
+
+	return true
+}
+
+// disconnectBanned encerra as conexões WebSocket da sala que correspondem à restrição.
+func (h apiHandler) disconnectBanned(rawRoomID string, ban pgstore.RoomBan) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	closeMessage := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "banned from room")
+	for conn, sub := range h.subscribers[rawRoomID] {
+		if !banMatches(ban, sub.participantID, sub.ip) {
+			continue
+		}
+
+		slog.Info("disconnecting banned client", "room_id", rawRoomID, "participant_id", sub.participantID, "client_ip", sub.ip)
+		_ = conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		sub.cancel() // Encerra a inscrição; handleSubscribe fecha a conexão
+	}
+}
+
+// handleGetRoomBans lista as restrições em vigor em uma sala.
+func (h apiHandler) handleGetRoomBans(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	bans, err := h.q.GetActiveRoomBans(r.Context(), roomID) // Obtém as restrições em vigor
+	if err != nil {
+		slog.Error("failed to get room bans", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if bans == nil {
+		bans = []pgstore.RoomBan{}
+	}
+
+	sendJSON(w, bans) // Envia a lista de restrições como resposta
+}
+
+// handleCreateRoomBan bane ou silencia um participante e/ou uma faixa de IPs em uma sala.
+func (h apiHandler) handleCreateRoomBan(w http.ResponseWriter, r *http.Request) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		ParticipantID   string `json:"participant_id"`
+		IPRange         string `json:"ip_range"`
+		Kind            string `json:"kind"`
+		Reason          string `json:"reason"`
+		DurationSeconds int64  `json:"duration_seconds"` // Zero indica uma restrição sem prazo
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if body.Kind != BanKindBan && body.Kind != BanKindMute {
+		http.Error(w, "invalid kind", http.StatusBadRequest)
+		return
+	}
+
+	if body.ParticipantID == "" && body.IPRange == "" {
+		http.Error(w, "participant_id or ip_range is required", http.StatusBadRequest)
+		return
+	}
+
+	// Os IDs de participante são emitidos pelo servidor (POST /participants)
+	if body.ParticipantID != "" {
+		if _, err := uuid.Parse(body.ParticipantID); err != nil {
+			http.Error(w, "invalid participant id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if body.IPRange != "" {
+		prefix, err := parseIPRange(body.IPRange)
+		if err != nil {
+			http.Error(w, "invalid ip range", http.StatusBadRequest)
+			return
+		}
+		body.IPRange = prefix.String()
+	}
+
+	if utf8.RuneCountInString(body.Reason) > 255 {
+		http.Error(w, "reason too long", http.StatusBadRequest)
+		return
+	}
+
+	if body.DurationSeconds < 0 {
+		http.Error(w, "invalid duration", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if body.DurationSeconds > 0 {
+		expiresAt = pgtype.Timestamptz{Time: time.Now().Add(time.Duration(body.DurationSeconds) * time.Second), Valid: true}
+	}
+
+	ban, err := h.q.InsertRoomBan(r.Context(), pgstore.InsertRoomBanParams{
+		RoomID:        roomID,
+		ParticipantID: body.ParticipantID,
+		IpRange:       body.IPRange,
+		Kind:          body.Kind,
+		Reason:        body.Reason,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		slog.Error("failed to insert room ban", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	sendJSON(w, ban) // Envia a restrição criada como resposta
+
+	// Participantes banidos perdem imediatamente as conexões abertas com a sala
+	if ban.Kind == BanKindBan {
+		go h.disconnectBanned(rawRoomID, ban)
+	}
+}
+
+// handleDeleteRoomBan remove uma restrição de uma sala.
+func (h apiHandler) handleDeleteRoomBan(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	banID, err := uuid.Parse(chi.URLParam(r, "ban_id")) // Obtém o ID da restrição da URL
+	if err != nil {
+		http.Error(w, "invalid ban id", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.q.DeleteRoomBan(r.Context(), pgstore.DeleteRoomBanParams{ID: banID, RoomID: roomID})
+	if err != nil {
+		slog.Error("failed to delete room ban", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if deleted == 0 {
+		http.Error(w, "ban not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK) // Envia status 200 OK
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+func TestParseIPRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"10.0.0.1", "10.0.0.1/32", false},
+		{"10.1.2.3/8", "10.0.0.0/8", false},
+		{"2001:db8::1", "2001:db8::1/128", false},
+		{"2001:db8::1/32", "2001:db8::/32", false},
+		{"::ffff:10.0.0.1", "10.0.0.1/32", false},
+		{"::ffff:10.1.2.3/104", "10.0.0.0/8", false},
+		{"::/0", "::/0", false},
+		{"10.0.0.1/33", "", true},
+		{"10.0.0", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseIPRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseIPRange(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.String() != tt.want {
+			t.Errorf("parseIPRange(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBanMatches(t *testing.T) {
+	const participant = "5b1c0e4e-8c1b-4a57-9a8e-3c0c1c7a2f10"
+	tests := []struct {
+		name        string
+		ban         pgstore.RoomBan
+		participant string
+		ip          string
+		want        bool
+	}{
+		{"participant", pgstore.RoomBan{ParticipantID: participant}, participant, "10.0.0.1", true},
+		{"other participant", pgstore.RoomBan{ParticipantID: participant}, "other", "10.0.0.1", false},
+		{"participant ban does not match clients without a token", pgstore.RoomBan{ParticipantID: participant}, "", "10.0.0.1", false},
+		{"single IP", pgstore.RoomBan{IpRange: "10.0.0.1/32"}, "", "10.0.0.1", true},
+		{"other IP", pgstore.RoomBan{IpRange: "10.0.0.1/32"}, "", "10.0.0.2", false},
+		{"IPv4 range", pgstore.RoomBan{IpRange: "10.0.0.0/8"}, "", "10.255.0.1", true},
+		{"outside the IPv4 range", pgstore.RoomBan{IpRange: "10.0.0.0/8"}, "", "11.0.0.1", false},
+		{"IPv4-mapped client", pgstore.RoomBan{IpRange: "10.0.0.0/8"}, "", "::ffff:10.0.0.1", true},
+		{"IPv6 range", pgstore.RoomBan{IpRange: "2001:db8::/32"}, "", "2001:db8:1::1", true},
+		{"IPv4 client and IPv6 range", pgstore.RoomBan{IpRange: "::/0"}, "", "10.0.0.1", false},
+		{"participant or IP", pgstore.RoomBan{ParticipantID: participant, IpRange: "10.0.0.0/8"}, "other", "10.0.0.1", true},
+		{"invalid client IP", pgstore.RoomBan{IpRange: "10.0.0.0/8"}, "", "not-an-ip", false},
+		{"invalid stored range", pgstore.RoomBan{IpRange: "10.0.0.0/99"}, "", "10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := banMatches(tt.ban, tt.participant, tt.ip); got != tt.want {
+				t.Errorf("banMatches(%+v, %q, %q) = %v, want %v", tt.ban, tt.participant, tt.ip, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// errPreconditionFailed indica que o recurso mudou desde a versão informada no cabeçalho If-Match.
+var errPreconditionFailed = errors.New("precondition failed")
+
+// contentETag retorna uma ETag forte calculada a partir do conteúdo da resposta.
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// versionETag retorna uma ETag forte derivada da versão de uma sala ou mensagem no banco de dados.
+// A ETag inclui a versão da API da requisição, já que cada versão da API tem uma representação diferente do recurso;
+// variant distingue representações diferentes da mesma versão, como a lista de mensagens vista pelos moderadores.
+func versionETag(ctx context.Context, version int64, variant string) string {
+	tag := "v" + strconv.Itoa(apiVersion(ctx)) + "." + strconv.FormatInt(version, 10)
+	if variant != "" {
+		tag += "-" + variant
+	}
+	return `"` + tag + `"`
+}
+
+// etagMatches indica se a ETag, que deve ser forte, consta na lista de um cabeçalho If-None-Match ou If-Match.
+// Na comparação fraca, usada pelo If-None-Match, o prefixo W/ das ETags da lista é ignorado;
+// na forte, usada pelo If-Match, ETags fracas nunca correspondem.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified define a ETag da resposta e, se a cópia do cliente identificada por If-None-Match ainda for válida,
+// responde com 304 Not Modified e retorna true.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+
+	match := r.Header.Get("If-None-Match")
+	if match == "" || !etagMatches(match, etag, true) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// notModifiedSince funciona como notModified e também considera o momento modified da última alteração do recurso,
+// que nunca deve voltar no tempo. Sem If-None-Match, que tem precedência, a cópia do cliente é válida se
+// If-Modified-Since não for anterior a modified, na precisão de segundos do cabeçalho.
+// Last-Modified só é enviado depois que o segundo de modified termina: antes disso, uma nova alteração no mesmo segundo
+// teria o mesmo Last-Modified, e o cliente manteria a cópia desatualizada.
+func notModifiedSince(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	modified = modified.UTC().Truncate(time.Second)
+	if modified.Before(time.Now().Truncate(time.Second)) {
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	}
+	if r.Header.Get("If-None-Match") != "" {
+		return notModified(w, r, etag)
+	}
+	w.Header().Set("ETag", etag)
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.After(since) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// ifMatch executa fn em uma transação na sala roomID (veja inRoomTx), verificando antes o cabeçalho If-Match contra
+// a ETag atual do recurso, obtida por lock. Com If-Match, lock é executada na mesma transação, depois do bloqueio da sala,
+// e deve bloquear o recurso, para que ele não mude entre a verificação e a alteração. Se a ETag não corresponder,
+// retorna errPreconditionFailed.
+// Sem WithDatabase não há transação, e fn é executada diretamente; com If-Match, é retornado errNoDatabase.
+func (h apiHandler) ifMatch(r *http.Request, roomID uuid.UUID, lock func(ctx context.Context, q pgstore.Querier) (string, error), fn func(q pgstore.Querier) error) error {
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		return h.inRoomTx(r.Context(), roomID, fn)
+	}
+
+	return h.inTx(r.Context(), lockingRoom(r.Context(), roomID, func(q pgstore.Querier) error {
+		etag, err := lock(r.Context(), q)
+		if err != nil {
+			return err
+		}
+		if !etagMatches(match, etag, false) {
+			return errPreconditionFailed
+		}
+		return fn(q)
+	}))
+}
+
+// inRoomTx executa fn em uma transação que começa bloqueando a sala roomID ou, sem WithDatabase, diretamente.
+//
+// Toda transação que altera mensagens de uma sala deve usá-la: o gatilho messages_bump_room_version bloqueia a sala
+// depois de cada mensagem alterada, e uma transação que altera várias mensagens (como demoteAnswering) ficaria com a sala
+// enquanto aguarda uma mensagem já bloqueada por outra transação, que por sua vez aguarda a sala. Bloqueando a sala
+// primeiro, as transações da sala são executadas em fila e nunca bloqueiam as mensagens em ordens diferentes.
+func (h apiHandler) inRoomTx(ctx context.Context, roomID uuid.UUID, fn func(q pgstore.Querier) error) error {
+	if h.db == nil {
+		return fn(h.q)
+	}
+	return h.inTx(ctx, lockingRoom(ctx, roomID, fn))
+}
+
+// lockingRoom retorna fn precedida do bloqueio da sala roomID.
+func lockingRoom(ctx context.Context, roomID uuid.UUID, fn func(q pgstore.Querier) error) func(q pgstore.Querier) error {
+	return func(q pgstore.Querier) error {
+		if _, err := q.LockRoomVersion(ctx, roomID); err != nil {
+			return err
+		}
+		return fn(q)
+	}
+}
+
+// lockRoom bloqueia a sala e retorna a sua ETag atual, para uso com ifMatch.
+func lockRoom(roomID uuid.UUID) func(ctx context.Context, q pgstore.Querier) (string, error) {
+	return func(ctx context.Context, q pgstore.Querier) (string, error) {
+		version, err := q.LockRoomVersion(ctx, roomID)
+		return versionETag(ctx, version, ""), err
+	}
+}
+
+// lockMessage bloqueia a mensagem da sala e retorna a sua ETag atual, para uso com ifMatch.
+func lockMessage(id, roomID uuid.UUID) func(ctx context.Context, q pgstore.Querier) (string, error) {
+	return func(ctx context.Context, q pgstore.Querier) (string, error) {
+		version, err := q.LockMessageVersion(ctx, pgstore.LockMessageVersionParams{ID: id, RoomID: roomID})
+		return versionETag(ctx, version, ""), err
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"v2.7"`
+	tests := []struct {
+		name   string
+		header string
+		weak   bool
+		want   bool
+	}{
+		{"strong exact", `"v2.7"`, false, true},
+		{"weak exact", `"v2.7"`, true, true},
+		{"strong other", `"v2.6"`, false, false},
+		{"weak other", `"v2.6"`, true, false},
+		{"strong against weak tag", `W/"v2.7"`, false, false},
+		{"weak against weak tag", `W/"v2.7"`, true, true},
+		{"strong wildcard", `*`, false, true},
+		{"weak wildcard", `*`, true, true},
+		{"list", `"v2.5", "v2.7"`, false, true},
+		{"list without spaces", `"v2.5","v2.7"`, false, true},
+		{"list of weak tags", `W/"v2.5", W/"v2.7"`, true, true},
+		{"list of weak tags strong", `W/"v2.5", W/"v2.7"`, false, false},
+		{"list without match", `"v2.5", "v2.6"`, true, false},
+		{"unquoted", `v2.7`, true, false},
+		{"other version of the api", `"v1.7"`, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag, tt.weak); got != tt.want {
+				t.Errorf("etagMatches(%q, %q, %v) = %v, want %v", tt.header, etag, tt.weak, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModifiedSince(t *testing.T) {
+	const etag = `"v2.7"`
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500_000_000, time.UTC)
+	lastModified := modified.Truncate(time.Second).Format(http.TimeFormat)
+	tests := []struct {
+		name      string
+		noneMatch string
+		since     string
+		want      bool
+	}{
+		{name: "no conditions"},
+		{name: "same second", since: lastModified, want: true},
+		{name: "later", since: modified.Add(time.Hour).Format(http.TimeFormat), want: true},
+		{name: "earlier", since: modified.Add(-time.Second).Format(http.TimeFormat)},
+		{name: "invalid date", since: "yesterday"},
+		{name: "etag matches", noneMatch: etag, want: true},
+		{name: "etag takes precedence", noneMatch: `"v2.6"`, since: lastModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.noneMatch != "" {
+				r.Header.Set("If-None-Match", tt.noneMatch)
+			}
+			if tt.since != "" {
+				r.Header.Set("If-Modified-Since", tt.since)
+			}
+			w := httptest.NewRecorder()
+
+			if got := notModifiedSince(w, r, etag, modified); got != tt.want {
+				t.Errorf("notModifiedSince = %v, want %v", got, tt.want)
+			}
+			wantStatus := http.StatusOK
+			if tt.want {
+				wantStatus = http.StatusNotModified
+			}
+			if w.Code != wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, wantStatus)
+			}
+			if got := w.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %q, want %q", got, etag)
+			}
+			if got := w.Header().Get("Last-Modified"); got != lastModified {
+				t.Errorf("Last-Modified = %q, want %q", got, lastModified)
+			}
+		})
+	}
+}
+
+func TestNotModifiedSinceWithinTheSecond(t *testing.T) {
+	// Enquanto o segundo da alteração não termina, Last-Modified não é enviado
+	modified := time.Now().Add(time.Second)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	if notModifiedSince(w, r, `"v2.7"`, modified) {
+		t.Error("notModifiedSince = true without conditions")
+	}
+	if got := w.Header().Get("Last-Modified"); got != "" {
+		t.Errorf("Last-Modified = %q, want none", got)
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Formatos de exportação de uma sala
+const (
+	ExportFormatJSON     = "json"
+	ExportFormatCSV      = "csv"
+	ExportFormatMarkdown = "md"
+)
+
+// exportFormats associa cada formato ao tipo de conteúdo da resposta e à função que o escreve.
+var exportFormats = map[string]struct {
+	contentType string
+	write       func(w io.Writer, version int, room pgstore.Room, messages []pgstore.Message, exportedAt time.Time) error
+}{
+	ExportFormatJSON:     {"application/json", writeExportJSON},
+	ExportFormatCSV:      {"text/csv; charset=utf-8", writeExportCSV},
+	ExportFormatMarkdown: {"text/markdown; charset=utf-8", writeExportMarkdown},
+}
+
+// handleExportRoom exporta a sala e as suas mensagens, ordenadas pelo número de reações.
+// O formato é escolhido pelo parâmetro format (json, csv ou md); a audiência recebe apenas as mensagens aprovadas.
+func (h apiHandler) handleExportRoom(w http.ResponseWriter, r *http.Request) {
+	room, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém a sala
+	if !ok {
+		return
+	}
+
+	format := cmp.Or(r.URL.Query().Get("format"), ExportFormatJSON)
+	exporter, ok := exportFormats[format]
+	if !ok {
+		http.Error(w, "invalid format", http.StatusBadRequest)
+		return
+	}
+
+	messages, err := h.visibleRoomMessages(r, roomID) // Obtém as mensagens visíveis para o cliente
+	if err != nil {
+		slog.Error("failed to get room messages", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	// As mais votadas primeiro; empates mantêm a ordem de criação
+	slices.SortStableFunc(messages, func(a, b pgstore.Message) int {
+		return cmp.Compare(b.ReactionCount, a.ReactionCount)
+	})
+
+	w.Header().Set("Content-Type", exporter.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="room-%s.%s"`, rawRoomID, format))
+
+	// As mensagens já estão todas em memória, mas a resposta é escrita diretamente em w; um erro aqui só pode ser registrado
+	if err := exporter.write(w, apiVersion(r.Context()), room, messages, time.Now().UTC()); err != nil {
+		slog.Warn("failed to write room export", "room_id", rawRoomID, "format", format, "error", err)
+	}
+}
+
+// writeExportJSON escreve a exportação como um objeto JSON, com a sala e as mensagens no formato da versão da API.
+func writeExportJSON(w io.Writer, version int, room pgstore.Room, messages []pgstore.Message, exportedAt time.Time) error {
+	header, err := json.Marshal(struct {
+		Room       any       `json:"room"`
+		ExportedAt time.Time `json:"exported_at"`
+	}{roomForVersion(version, room), exportedAt})
+	if err != nil {
+		return err
+	}
+
+	// Reaproveita o objeto do cabeçalho, abrindo nele a lista de mensagens
+	if _, err := fmt.Fprintf(w, "%s,\"messages\":[", header[:len(header)-1]); err != nil {
+		return err
+	}
+	for i, message := range messages {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(messageForVersion(version, message))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "]}\n")
+	return err
+}
+
+// writeExportCSV escreve a exportação em CSV, com os dados da sala repetidos em cada linha.
+// Os textos enviados pelos clientes passam por csvEscape.
+func writeExportCSV(w io.Writer, _ int, room pgstore.Room, messages []pgstore.Message, _ time.Time) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"room_id", "room_theme", "id", "message", "reaction_count", "answered", "moderation_status", "attribution", "status"})
+	for _, m := range messages {
+		_ = cw.Write([]string{
+			room.ID.String(),
+			csvEscape(room.Theme),
+			m.ID.String(),
+			csvEscape(m.Message),
+			strconv.FormatInt(m.ReactionCount, 10),
+			strconv.FormatBool(m.Answered),
+			m.ModerationStatus,
+			csvEscape(m.Attribution),
+			m.Status,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvEscape impede que planilhas interpretem a célula como fórmula (CSV injection), prefixando com um apóstrofo
+// os textos que começam com um dos caracteres que iniciam fórmulas.
+func csvEscape(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// writeExportMarkdown escreve a exportação como um documento Markdown com uma tabela de perguntas.
+func writeExportMarkdown(w io.Writer, _ int, room pgstore.Room, messages []pgstore.Message, exportedAt time.Time) error {
+	if _, err := fmt.Fprintf(w, "# %s\n\nExported at %s · %d questions\n\n| # | Votes | Answered | Question |\n|---|---|---|---|\n",
+		markdownEscape(room.Theme), exportedAt.Format(time.RFC3339), len(messages)); err != nil {
+		return err
+	}
+
+	for i, m := range messages {
+		answered := ""
+		if m.Answered {
+			answered = "✅"
+		}
+		question := markdownEscape(m.Message)
+		if m.Attribution != "" {
+			question += " — _via " + markdownEscape(m.Attribution) + "_"
+		}
+		if m.ModerationStatus != ModerationStatusApproved {
+			question += " _(" + m.ModerationStatus + ")_"
+		}
+		if m.Status == MessageStatusDismissed || m.Status == MessageStatusDuplicate {
+			question += " _(" + m.Status + ")_"
+		}
+		if _, err := fmt.Fprintf(w, "| %d | %d | %s | %s |\n", i+1, m.ReactionCount, answered, question); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownReplacer escapa os caracteres que quebrariam a tabela ou seriam interpretados como formatação.
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", ">", "&gt;",
+	"\r\n", "<br>", "\n", "<br>", "\r", "<br>",
+)
+
+// markdownEscape prepara um texto para ser incluído em uma célula da tabela.
+func markdownEscape(s string) string {
+	return markdownReplacer.Replace(s)
+}
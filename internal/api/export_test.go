@@ -0,0 +1,55 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+func TestCSVEscape(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"question", "question"},
+		{"=HYPERLINK(\"http://example.com\")", "'=HYPERLINK(\"http://example.com\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+		{" =1", " =1"},
+	}
+	for _, tt := range tests {
+		if got := csvEscape(tt.in); got != tt.want {
+			t.Errorf("csvEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteExportCSVEscapesFormulas(t *testing.T) {
+	room := pgstore.Room{ID: uuid.New(), Theme: "=theme"}
+	messages := []pgstore.Message{{ID: uuid.New(), RoomID: room.ID, Message: "=cmd|' /C calc'!A0", Attribution: "@bot", Status: MessageStatusOpen}}
+
+	var buf bytes.Buffer
+	if err := writeExportCSV(&buf, APIVersion2, room, messages, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want header and 1 message", len(records))
+	}
+	row := records[1]
+	if row[1] != "'=theme" || row[3] != "'=cmd|' /C calc'!A0" || row[7] != "'@bot" {
+		t.Errorf("row = %q, want theme, message and attribution prefixed with '", row)
+	}
+}
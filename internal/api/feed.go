@@ -0,0 +1,277 @@
+package api
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// maxFeedEntries é o número máximo de mensagens incluídas em um feed, das atualizadas mais recentemente.
+const maxFeedEntries = 100
+
+// maxFeedTitleLength é o tamanho do título de uma entrada; o texto completo vai no conteúdo.
+const maxFeedTitleLength = 80
+
+// feedEntry é uma mensagem no formato comum aos feeds Atom e RSS.
+type feedEntry struct {
+	message   pgstore.Message
+	published time.Time // Criação da mensagem
+	updated   time.Time // Resposta da mensagem ou, se ainda não foi respondida, a sua criação
+}
+
+// Estrutura do documento Atom (RFC 4287)
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+// Estrutura do documento RSS 2.0
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator,omitempty"` // O <author> do RSS exige um e-mail
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+// handleGetRoomAtomFeed retorna as perguntas aprovadas da sala como um feed Atom.
+func (h apiHandler) handleGetRoomAtomFeed(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "application/atom+xml; charset=utf-8", encodeAtomFeed)
+}
+
+// handleGetRoomRSSFeed retorna as perguntas aprovadas da sala como um feed RSS 2.0.
+func (h apiHandler) handleGetRoomRSSFeed(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "application/rss+xml; charset=utf-8", encodeRSSFeed)
+}
+
+// serveFeed monta o feed da sala com encode e responde com suporte a GET condicional pela ETag do conteúdo e por
+// Last-Modified. Last-Modified é a última alteração da sala ou das suas mensagens, e não a data da entrada mais recente,
+// que pode voltar no tempo, por exemplo quando uma resposta é desfeita ou uma mensagem é ocultada.
+// O feed é público: contém apenas as mensagens aprovadas, mesmo para moderadores. Com answered=true,
+// apenas as perguntas já respondidas são incluídas.
+func (h apiHandler) serveFeed(w http.ResponseWriter, r *http.Request, contentType string, encode func(self string, room pgstore.Room, entries []feedEntry, updated time.Time) any) {
+	room, _, roomID, ok := h.readRoom(w, r) // Obtém a sala
+	if !ok {
+		return
+	}
+
+	answeredOnly := false
+	if raw := r.URL.Query().Get("answered"); raw != "" {
+		var err error
+		if answeredOnly, err = strconv.ParseBool(raw); err != nil {
+			http.Error(w, "invalid answered", http.StatusBadRequest)
+			return
+		}
+	}
+
+	messages, err := h.q.GetRoomMessagesByModerationStatus(r.Context(), pgstore.GetRoomMessagesByModerationStatusParams{
+		RoomID:           roomID,
+		ModerationStatus: ModerationStatusApproved,
+	})
+	if err != nil {
+		slog.Error("failed to get room messages", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	entries, updated := feedEntries(messages, answeredOnly)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(encode(feedURL(r), room, entries, updated)); err != nil {
+		slog.Error("failed to encode room feed", "room_id", roomID, "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	// Os leitores de feed revalidam a cada consulta; a ETag e Last-Modified evitam reenviar um feed que não mudou.
+	// A sala é lida antes das mensagens, então uma alteração no intervalo só torna Last-Modified mais antigo que o conteúdo
+	w.Header().Set("Cache-Control", "no-cache")
+	if notModifiedSince(w, r, contentETag(buf.Bytes()), room.UpdatedAt.Time) {
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// feedEntries seleciona as mensagens do feed, das atualizadas mais recentemente para as mais antigas,
+// e retorna também o momento da atualização mais recente.
+func feedEntries(messages []pgstore.Message, answeredOnly bool) ([]feedEntry, time.Time) {
+	entries := make([]feedEntry, 0, len(messages))
+	for _, m := range messages {
+		if answeredOnly && !m.Answered {
+			continue
+		}
+
+		entry := feedEntry{message: m, published: m.CreatedAt.Time, updated: m.CreatedAt.Time}
+		if m.AnsweredAt.Valid {
+			entry.updated = m.AnsweredAt.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b feedEntry) int {
+		return cmp.Or(b.updated.Compare(a.updated), b.published.Compare(a.published))
+	})
+	entries = entries[:min(len(entries), maxFeedEntries)]
+
+	var updated time.Time
+	if len(entries) > 0 {
+		updated = entries[0].updated
+	}
+	return entries, updated
+}
+
+func encodeAtomFeed(self string, room pgstore.Room, entries []feedEntry, updated time.Time) any {
+	if updated.IsZero() {
+		updated = time.Unix(0, 0) // O Atom exige <updated> mesmo em feeds vazios
+	}
+
+	feed := atomFeed{
+		ID:      uuid.NewSHA1(uuid.NameSpaceURL, []byte(self)).URN(), // Estável para cada variante do feed
+		Title:   room.Theme,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomPerson{Name: "wsrs"},
+		Link:    atomLink{Rel: "self", Href: self},
+		Entries: make([]atomEntry, 0, len(entries)),
+	}
+
+	for _, e := range entries {
+		entry := atomEntry{
+			ID:         e.message.ID.URN(),
+			Title:      feedTitle(e.message.Message),
+			Published:  e.published.UTC().Format(time.RFC3339),
+			Updated:    e.updated.UTC().Format(time.RFC3339),
+			Categories: []atomCategory{{Term: feedCategory(e.message)}},
+			Content:    atomContent{Type: "text", Body: e.message.Message},
+		}
+		if e.message.Attribution != "" {
+			entry.Author = &atomPerson{Name: e.message.Attribution}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+func encodeRSSFeed(self string, room pgstore.Room, entries []feedEntry, updated time.Time) any {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       room.Theme,
+			Link:        self,
+			Description: "Questions of the room " + room.Theme,
+			Items:       make([]rssItem, 0, len(entries)),
+		},
+	}
+	if !updated.IsZero() {
+		feed.Channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, e := range entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       feedTitle(e.message.Message),
+			GUID:        rssGUID{Value: e.message.ID.URN()},
+			PubDate:     e.published.UTC().Format(time.RFC1123Z),
+			Creator:     e.message.Attribution,
+			Categories:  []string{feedCategory(e.message)},
+			Description: e.message.Message,
+		})
+	}
+	return feed
+}
+
+// feedCategory classifica a entrada como respondida ou não.
+func feedCategory(m pgstore.Message) string {
+	if m.Answered {
+		return "answered"
+	}
+	return "unanswered"
+}
+
+// feedTitle encurta o texto da mensagem para uso como título.
+func feedTitle(s string) string {
+	if utf8.RuneCountInString(s) <= maxFeedTitleLength {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxFeedTitleLength-1]) + "…"
+}
+
+// feedURL reconstrói o endereço absoluto do feed, incluindo a variante escolhida, para o link self.
+func feedURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	u := scheme + "://" + r.Host + r.URL.Path
+	if r.URL.RawQuery != "" {
+		u += "?" + r.URL.RawQuery
+	}
+	return u
+}
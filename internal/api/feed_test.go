@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/joao-ressel/go-server/internal/config"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+	"github.com/joao-ressel/go-server/internal/store/pgstore/pgstoretest"
+)
+
+// TestFeedLastModified verifica que o Last-Modified do feed avança quando uma resposta é desfeita,
+// embora a entrada mais recente do feed volte no tempo.
+func TestFeedLastModified(t *testing.T) {
+	mem := pgstoretest.NewMemory()
+	checkFeedLastModified(t, mem, mem)
+}
+
+// TestFeedLastModifiedPostgres repete TestFeedLastModified com um banco de dados real, em que o momento da última
+// alteração da sala é mantido pelo gatilho rooms_touch (veja pgstoretest.EnvDatabaseURL).
+func TestFeedLastModifiedPostgres(t *testing.T) {
+	pool := pgstoretest.New(t)
+	checkFeedLastModified(t, pgstore.New(pool), pool)
+}
+
+func checkFeedLastModified(t *testing.T, q pgstore.Querier, db DB) {
+	h := NewHandler(q,
+		WithDatabase(db),
+		WithRateLimit(config.RateLimitConfig{Default: config.RateLimit{Rate: 1000, Burst: 1000}}),
+	)
+
+	var created struct {
+		ID             string `json:"id"`
+		ModeratorToken string `json:"moderator_token"`
+	}
+	serve(t, h, http.MethodPost, "/rooms", map[string]any{"theme": "feeds"}, nil, &created)
+	room := "/rooms/" + created.ID
+	mod := http.Header{"Authorization": {"Bearer " + created.ModeratorToken}}
+
+	var message struct {
+		ID string `json:"id"`
+	}
+	serve(t, h, http.MethodPost, room+"/messages", map[string]any{"message": "question"}, nil, &message)
+	status := room + "/messages/" + message.ID + "/status"
+	if rec := serve(t, h, http.MethodPatch, status, map[string]any{"status": MessageStatusAnswered}, mod, nil); rec.Code != http.StatusOK {
+		t.Fatalf("answer: %d %s", rec.Code, rec.Body)
+	}
+
+	// Last-Modified só é enviado depois que termina o segundo da última alteração
+	if rec := serve(t, h, http.MethodGet, room+"/feed.atom", nil, nil, nil); rec.Header().Get("Last-Modified") != "" {
+		t.Skip("the second of the last change ended before the request") // Muito improvável, mas não é um erro
+	}
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+	rec := serve(t, h, http.MethodGet, room+"/feed.atom", nil, nil, nil)
+	lastModified, err := http.ParseTime(rec.Header().Get("Last-Modified"))
+	if err != nil {
+		t.Fatalf("Last-Modified: %v", err)
+	}
+	since := http.Header{"If-Modified-Since": {lastModified.Format(http.TimeFormat)}}
+	if rec := serve(t, h, http.MethodGet, room+"/feed.atom", nil, since, nil); rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since Last-Modified: %d, want 304", rec.Code)
+	}
+
+	// Desfeita a resposta, a entrada volta para a data de criação, mas o feed mudou
+	if rec := serve(t, h, http.MethodPatch, status, map[string]any{"status": MessageStatusOpen}, mod, nil); rec.Code != http.StatusOK {
+		t.Fatalf("reopen: %d %s", rec.Code, rec.Body)
+	}
+	if rec := serve(t, h, http.MethodGet, room+"/feed.atom", nil, since, nil); rec.Code != http.StatusOK {
+		t.Errorf("If-Modified-Since after reopening: %d, want 200", rec.Code)
+	}
+
+	// If-None-Match tem precedência sobre If-Modified-Since
+	etag := http.Header{"If-None-Match": {`"stale"`}, "If-Modified-Since": {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}}
+	if rec := serve(t, h, http.MethodGet, room+"/feed.atom", nil, etag, nil); rec.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match with a future If-Modified-Since: %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/filter"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// WithContentFilter substitui o filtro de conteúdo aplicado às novas mensagens.
+// Por padrão são aplicadas apenas as regras cadastradas em cada sala.
+func WithContentFilter(f filter.Filter) Option {
+	return func(h *apiHandler) {
+		h.filter = f
+	}
+}
+
+// handleGetRoomFilterRules lista as regras do filtro de conteúdo de uma sala.
+func (h apiHandler) handleGetRoomFilterRules(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	rules, err := h.q.GetRoomFilterRules(r.Context(), roomID) // Obtém as regras da sala
+	if err != nil {
+		slog.Error("failed to get filter rules", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if rules == nil {
+		rules = []pgstore.RoomFilterRule{}
+	}
+
+	sendJSON(w, rules) // Envia a lista de regras como resposta
+}
+
+// handleCreateRoomFilterRule adiciona uma regra ao filtro de conteúdo de uma sala.
+func (h apiHandler) handleCreateRoomFilterRule(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		Pattern string `json:"pattern"`
+		Regex   bool   `json:"regex"`
+		Action  string `json:"action"`
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	action, err := filter.ParseAction(body.Action)
+	if err != nil {
+		http.Error(w, "invalid action", http.StatusBadRequest)
+		return
+	}
+
+	rule := filter.Rule{Pattern: body.Pattern, Regex: body.Regex, Action: action}
+	if _, err := rule.Compile(); err != nil || body.Pattern == "" || len(body.Pattern) > 255 {
+		http.Error(w, "invalid pattern", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.q.InsertRoomFilterRule(r.Context(), pgstore.InsertRoomFilterRuleParams{
+		RoomID:  roomID,
+		Pattern: rule.Pattern,
+		IsRegex: rule.Regex,
+		Action:  string(rule.Action),
+	})
+	if err != nil {
+		slog.Error("failed to insert filter rule", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	sendJSON(w, created) // Envia a regra criada como resposta
+}
+
+// handleDeleteRoomFilterRule remove uma regra do filtro de conteúdo de uma sala.
+func (h apiHandler) handleDeleteRoomFilterRule(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	ruleID, err := uuid.Parse(chi.URLParam(r, "rule_id")) // Obtém o ID da regra da URL
+	if err != nil {
+		http.Error(w, "invalid rule id", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.q.DeleteRoomFilterRule(r.Context(), pgstore.DeleteRoomFilterRuleParams{ID: ruleID, RoomID: roomID})
+	if err != nil {
+		slog.Error("failed to delete filter rule", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if deleted == 0 {
+		http.Error(w, "rule not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK) // Envia status 200 OK
+}
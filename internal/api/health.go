@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// DB é a conexão usada pelas verificações de prontidão e pelas operações que exigem uma transação;
+// *pgxpool.Pool satisfaz esta interface.
+type DB interface {
+	Ping(ctx context.Context) error
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithDatabase habilita as verificações de banco de dados e de migrações em /readyz,
+// além da importação e da clonagem de salas, que são feitas em uma única transação.
+func WithDatabase(db DB) Option {
+	return func(h *apiHandler) {
+		h.db = db
+	}
+}
+
+// WithReadinessTimeout altera o tempo máximo de cada verificação de dependência em /readyz.
+func WithReadinessTimeout(d time.Duration) Option {
+	return func(h *apiHandler) {
+		h.readinessTimeout = d
+	}
+}
+
+// Estados das verificações de saúde
+const (
+	checkStatusOK   = "ok"
+	checkStatusFail = "fail"
+)
+
+// healthCheck é o resultado de uma verificação individual.
+type healthCheck struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	Version    *int   `json:"version,omitempty"`  // Versão do esquema aplicada (apenas migrations)
+	Expected   *int   `json:"expected,omitempty"` // Versão do esquema esperada (apenas migrations)
+}
+
+// healthReport é o corpo das respostas de /healthz e /readyz.
+type healthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]healthCheck `json:"checks,omitempty"`
+}
+
+// runCheck executa uma verificação com o prazo de prontidão e mede a sua duração.
+func (h apiHandler) runCheck(ctx context.Context, check func(ctx context.Context) error) healthCheck {
+	ctx, cancel := context.WithTimeout(ctx, h.readinessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := healthCheck{Status: checkStatusOK, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status, result.Error = checkStatusFail, err.Error()
+	}
+	return result
+}
+
+// handleHealthz responde à verificação de vida: o processo está no ar e atendendo requisições.
+func (h apiHandler) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, healthReport{Status: checkStatusOK})
+}
+
+// handleReadyz responde à verificação de prontidão, detalhando o resultado de cada dependência.
+// Responde 503 se alguma verificação falhar, para que o orquestrador deixe de enviar tráfego.
+func (h apiHandler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := healthReport{Status: checkStatusOK, Checks: map[string]healthCheck{}}
+
+	// O servidor deixa de estar pronto assim que começa a encerrar
+	report.Checks["draining"] = healthCheck{Status: checkStatusOK}
+	if h.draining.Load() {
+		report.Checks["draining"] = healthCheck{Status: checkStatusFail, Error: "server is shutting down"}
+	}
+
+	if h.db != nil {
+		report.Checks["database"] = h.runCheck(r.Context(), h.db.Ping)
+
+		var version int
+		expected := pgstore.SchemaVersion()
+		check := h.runCheck(r.Context(), func(ctx context.Context) error {
+			if err := h.db.QueryRow(ctx, "SELECT version FROM schema_version").Scan(&version); err != nil {
+				return err
+			}
+			if version != expected {
+				return fmt.Errorf("schema version %d, expected %d", version, expected)
+			}
+			return nil
+		})
+		check.Version, check.Expected = &version, &expected
+		report.Checks["migrations"] = check
+	}
+
+	for _, check := range report.Checks {
+		if check.Status != checkStatusOK {
+			report.Status = checkStatusFail
+		}
+	}
+
+	if report.Status != checkStatusOK {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	sendJSON(w, report)
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// handlePinMessage fixa uma mensagem aprovada no topo da sala.
+func (h apiHandler) handlePinMessage(w http.ResponseWriter, r *http.Request) {
+	h.pinMessage(w, r, true)
+}
+
+// handleUnpinMessage desafixa uma mensagem.
+func (h apiHandler) handleUnpinMessage(w http.ResponseWriter, r *http.Request) {
+	h.pinMessage(w, r, false)
+}
+
+// pinMessage fixa ou desafixa uma mensagem e notifica os clientes.
+func (h apiHandler) pinMessage(w http.ResponseWriter, r *http.Request, pinned bool) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	rawID := chi.URLParam(r, "message_id") // Obtém o ID da mensagem da URL
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		http.Error(w, "invalid message id", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		message pgstore.Message
+		event   Message
+	)
+	err = h.ifMatch(r, roomID, lockMessage(id, roomID), func(q pgstore.Querier) (err error) {
+		message, err = q.SetMessagePinned(r.Context(), pgstore.SetMessagePinnedParams{Pinned: pinned, ID: id, RoomID: roomID})
+		if err != nil {
+			return err
+		}
+
+		// Notifica os clientes assinantes da sala sobre a mensagem fixada ou desafixada
+		event = Message{
+			Kind:   MessageKindMessagePinned,
+			RoomID: rawRoomID,
+			Value: MessageMessagePinned{
+				ID:     rawID,
+				Pinned: pinned,
+			},
+		}
+		return enqueueWebhooks(r.Context(), q, event)
+	})
+	if err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			http.Error(w, "message has changed", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "approved message not found", http.StatusNotFound)
+			return
+		}
+
+		slog.Error("failed to pin message", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(r.Context(), message.Version, ""))
+	sendJSON(w, messageForVersion(apiVersion(r.Context()), message)) // Envia a mensagem atualizada como resposta
+
+	go h.notifyClients(r.Context(), event)
+}
+
+// handleSetNowAnswering define a mensagem que está sendo respondida, exibida em destaque para a audiência.
+// A mensagem passa para o estado "answering". Há no máximo uma por sala; a anterior deixa de estar em destaque e volta para "open".
+// Como altera várias mensagens, a transação bloqueia a sala antes delas, com ou sem If-Match (veja inRoomTx).
+func (h apiHandler) handleSetNowAnswering(w http.ResponseWriter, r *http.Request) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		MessageID uuid.UUID `json:"message_id"`
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		room      pgstore.Room
+		oldStatus string
+		events    []Message
+	)
+	err := h.ifMatch(r, roomID, lockRoom(roomID), func(q pgstore.Querier) (err error) {
+		var message pgstore.Message
+		message, oldStatus, events, err = applyMessageStatus(r.Context(), q, rawRoomID, roomID, body.MessageID, MessageStatusAnswering)
+		if err != nil {
+			return err
+		}
+		if oldStatus == MessageStatusAnswering {
+			// A mensagem já estava sendo respondida; o destaque é definido de novo
+			if events, err = linkNowAnswering(r.Context(), q, rawRoomID, message, oldStatus); err != nil {
+				return err
+			}
+		}
+
+		if room, err = q.GetRoom(r.Context(), roomID); err != nil {
+			return err
+		}
+		return enqueueWebhooks(r.Context(), q, events...)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errPreconditionFailed):
+			http.Error(w, "room has changed", http.StatusPreconditionFailed)
+		case errors.Is(err, pgx.ErrNoRows), errors.Is(err, errNotApproved):
+			http.Error(w, "approved message not found", http.StatusNotFound)
+		case errors.Is(err, errInvalidStatusTransition):
+			http.Error(w, fmt.Sprintf("cannot change status from %s to %s", oldStatus, MessageStatusAnswering), http.StatusConflict)
+		case errors.Is(err, errStatusChanged):
+			http.Error(w, "message status has changed", http.StatusConflict)
+		default:
+			slog.Error("failed to set now answering message", "error", err)
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(r.Context(), room.Version, ""))
+	sendJSON(w, roomForVersion(apiVersion(r.Context()), room)) // Envia a sala atualizada como resposta
+
+	go func() {
+		for _, msg := range events {
+			h.notifyClients(r.Context(), msg) // Enviados em ordem
+		}
+	}()
+}
+
+// handleClearNowAnswering remove o destaque da mensagem que estava sendo respondida, que volta para o estado "open".
+// Assim como em handleSetNowAnswering, a sala é bloqueada no início da transação, com ou sem If-Match.
+func (h apiHandler) handleClearNowAnswering(w http.ResponseWriter, r *http.Request) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	var (
+		room   pgstore.Room
+		events []Message
+	)
+	err := h.ifMatch(r, roomID, lockRoom(roomID), func(q pgstore.Querier) (err error) {
+		events, err = demoteAnswering(r.Context(), q, rawRoomID, roomID, uuid.Nil)
+		if err != nil {
+			return err
+		}
+
+		room, err = q.ClearRoomNowAnswering(r.Context(), roomID)
+		if err != nil {
+			return err
+		}
+
+		// Notifica os clientes assinantes da sala de que nenhuma mensagem está sendo respondida
+		events = append(events, Message{
+			Kind:   MessageKindNowAnswering,
+			RoomID: rawRoomID,
+			Value:  MessageNowAnswering{},
+		})
+		return enqueueWebhooks(r.Context(), q, events...)
+	})
+	if err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			http.Error(w, "room has changed", http.StatusPreconditionFailed)
+			return
+		}
+
+		slog.Error("failed to clear now answering message", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(r.Context(), room.Version, ""))
+	sendJSON(w, roomForVersion(apiVersion(r.Context()), room)) // Envia a sala atualizada como resposta
+
+	go func() {
+		for _, msg := range events {
+			h.notifyClients(r.Context(), msg) // Enviados em ordem
+		}
+	}()
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/joao-ressel/go-server/internal/config"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+	"github.com/joao-ressel/go-server/internal/store/pgstore/pgstoretest"
+)
+
+// TestNowAnsweringConcurrentWithReactions troca e remove o destaque da sala, sem If-Match, enquanto as mensagens
+// recebem reações. Cada troca volta a mensagem anterior para "open"; sem o bloqueio da sala no início da transação,
+// ela disputa as mensagens com as reações em ordem inversa e o PostgreSQL aborta uma das transações por deadlock.
+func TestNowAnsweringConcurrentWithReactions(t *testing.T) {
+	pool := pgstoretest.New(t)
+	h := NewHandler(pgstore.New(pool),
+		WithDatabase(pool),
+		WithRateLimit(config.RateLimitConfig{Default: config.RateLimit{Rate: 1000, Burst: 1000}}),
+	)
+
+	var created struct {
+		ID             string `json:"id"`
+		ModeratorToken string `json:"moderator_token"`
+	}
+	serve(t, h, http.MethodPost, "/rooms", map[string]any{"theme": "now answering"}, nil, &created)
+	room := "/rooms/" + created.ID
+	mod := http.Header{"Authorization": {"Bearer " + created.ModeratorToken}}
+
+	ids := make([]string, 3)
+	for i := range ids {
+		var message struct {
+			ID string `json:"id"`
+		}
+		serve(t, h, http.MethodPost, room+"/messages", map[string]any{"message": "question"}, nil, &message)
+		ids[i] = message.ID
+	}
+
+	const rounds = 50
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range rounds {
+			rec := serve(t, h, http.MethodPut, room+"/now-answering", map[string]any{"message_id": ids[i%len(ids)]}, mod, nil)
+			if rec.Code != http.StatusOK {
+				t.Errorf("set now answering: %d %s", rec.Code, rec.Body)
+				return
+			}
+			if i%5 == 2 {
+				if rec := serve(t, h, http.MethodDelete, room+"/now-answering", nil, mod, nil); rec.Code != http.StatusOK {
+					t.Errorf("clear now answering: %d %s", rec.Code, rec.Body)
+					return
+				}
+			}
+		}
+	}()
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range rounds {
+				for _, method := range []string{http.MethodPatch, http.MethodDelete} {
+					if rec := serve(t, h, method, room+"/messages/"+id+"/react", nil, nil, nil); rec.Code != http.StatusOK {
+						t.Errorf("%s react: %d %s", method, rec.Code, rec.Body)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A última troca (rounds-1) destacou ids[(rounds-1)%len(ids)], e ela é a única mensagem sendo respondida
+	want := ids[(rounds-1)%len(ids)]
+	var details struct {
+		NowAnsweringID *string `json:"now_answering_id"`
+	}
+	serve(t, h, http.MethodGet, room, nil, nil, &details)
+	if details.NowAnsweringID == nil || *details.NowAnsweringID != want {
+		t.Errorf("now_answering_id = %v, want %s", details.NowAnsweringID, want)
+	}
+	for _, id := range ids {
+		var m struct {
+			Status string `json:"status"`
+		}
+		serve(t, h, http.MethodGet, room+"/messages/"+id, nil, nil, &m)
+		if wantStatus := map[bool]string{true: MessageStatusAnswering, false: MessageStatusOpen}[id == want]; m.Status != wantStatus {
+			t.Errorf("message %s status = %s, want %s", id, m.Status, wantStatus)
+		}
+	}
+}
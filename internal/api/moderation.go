@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Estados de moderação de uma mensagem
+const (
+	ModerationStatusPending  = "pending"  // Aguardando aprovação de um moderador
+	ModerationStatusApproved = "approved" // Visível para a audiência
+	ModerationStatusRejected = "rejected" // Rejeitada por um moderador
+)
+
+// newModeratorToken gera um token aleatório de moderador e o seu hash para armazenamento.
+func newModeratorToken() (token, tokenHash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token = hex.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// RotateModeratorToken emite um novo token de moderador para a sala, invalidando o anterior, e o retorna em texto.
+// Serve também às salas criadas antes dos tokens de moderador, que não têm nenhum. Retorna pgx.ErrNoRows se a sala não existir.
+func RotateModeratorToken(ctx context.Context, q pgstore.Querier, roomID uuid.UUID) (string, error) {
+	if _, err := q.GetRoom(ctx, roomID); err != nil {
+		return "", err
+	}
+
+	token, tokenHash, err := newModeratorToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := q.UpsertRoomModeratorToken(ctx, pgstore.UpsertRoomModeratorTokenParams{RoomID: roomID, TokenHash: tokenHash}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// hashToken retorna o hash SHA-256 em hexadecimal de um token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// moderatorTokenParam é o parâmetro de consulta com o token de moderador, aceito apenas na abertura de conexões WebSocket,
+// já que navegadores não permitem definir o cabeçalho Authorization nelas.
+const moderatorTokenParam = "moderator_token"
+
+// moderatorToken obtém o token de moderador enviado na requisição.
+// O token é lido do cabeçalho Authorization (Bearer) ou, para conexões WebSocket, do parâmetro moderator_token.
+// Nas demais requisições o parâmetro é ignorado, para que o token não apareça em URLs de links, históricos e logs.
+func moderatorToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if !websocket.IsWebSocketUpgrade(r) {
+		return ""
+	}
+	return r.URL.Query().Get(moderatorTokenParam)
+}
+
+// redactModeratorToken substitui o token de moderador em RequestURI, que é a URL registrada por middleware.Logger.
+// A URL usada pelos handlers, r.URL, não é alterada.
+func redactModeratorToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Has(moderatorTokenParam) {
+			query.Set(moderatorTokenParam, "REDACTED")
+			r = r.Clone(r.Context())
+			r.RequestURI = r.URL.EscapedPath() + "?" + query.Encode()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isModerator verifica se a requisição apresenta o token de moderador da sala.
+func (h apiHandler) isModerator(r *http.Request, roomID uuid.UUID) bool {
+	token := moderatorToken(r)
+	if token == "" {
+		return false
+	}
+
+	tokenHash, err := h.q.GetRoomModeratorTokenHash(r.Context(), roomID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			slog.Error("failed to get moderator token", "error", err)
+		}
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(tokenHash)) == 1
+}
+
+// requireModerator responde com erro e retorna false se a requisição não for de um moderador da sala.
+func (h apiHandler) requireModerator(w http.ResponseWriter, r *http.Request, roomID uuid.UUID) bool {
+	if moderatorToken(r) == "" {
+		http.Error(w, "missing moderator token", http.StatusUnauthorized)
+		return false
+	}
+
+	if !h.isModerator(r, roomID) {
+		http.Error(w, "invalid moderator token", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// handleUpdateRoomSettings altera as configurações de uma sala.
+func (h apiHandler) handleUpdateRoomSettings(w http.ResponseWriter, r *http.Request) {
+	room, _, roomID, ok := h.readRoom(w, r) // Obtém a sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		Moderated *bool `json:"moderated"`
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	err := h.ifMatch(r, roomID, lockRoom(roomID), func(q pgstore.Querier) (err error) {
+		if body.Moderated != nil {
+			room, err = q.SetRoomModerated(r.Context(), pgstore.SetRoomModeratedParams{ID: roomID, Moderated: *body.Moderated})
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			http.Error(w, "room has changed", http.StatusPreconditionFailed)
+			return
+		}
+
+		slog.Error("failed to update room settings", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(r.Context(), room.Version, ""))
+	sendJSON(w, roomForVersion(apiVersion(r.Context()), room)) // Envia a sala atualizada como resposta
+}
+
+// handleApproveMessage aprova uma mensagem pendente e a publica para a audiência.
+func (h apiHandler) handleApproveMessage(w http.ResponseWriter, r *http.Request) {
+	h.moderateMessage(w, r, ModerationStatusApproved)
+}
+
+// handleRejectMessage rejeita uma mensagem pendente.
+func (h apiHandler) handleRejectMessage(w http.ResponseWriter, r *http.Request) {
+	h.moderateMessage(w, r, ModerationStatusRejected)
+}
+
+// moderateMessage altera o estado de moderação de uma mensagem pendente e notifica os clientes.
+func (h apiHandler) moderateMessage(w http.ResponseWriter, r *http.Request, status string) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	rawID := chi.URLParam(r, "message_id") // Obtém o ID da mensagem da URL
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		http.Error(w, "invalid message id", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		message pgstore.Message
+		event   Message
+	)
+	err = h.ifMatch(r, roomID, lockMessage(id, roomID), func(q pgstore.Querier) (err error) {
+		message, err = q.UpdatePendingMessageModerationStatus(r.Context(), pgstore.UpdatePendingMessageModerationStatusParams{
+			ID:               id,
+			RoomID:           roomID,
+			ModerationStatus: status,
+		})
+		if err != nil {
+			return err
+		}
+		if err := q.MarkMessageReportsReviewed(r.Context(), id); err != nil { // As denúncias foram resolvidas pela decisão do moderador
+			return err
+		}
+
+		if status == ModerationStatusRejected {
+			// Notifica os demais moderadores para que removam a mensagem da fila
+			event = Message{
+				Kind:           MessageKindMessageRejected,
+				RoomID:         rawRoomID,
+				ModeratorsOnly: true,
+				Value: MessageMessageRejected{
+					ID: rawID,
+				},
+			}
+		} else {
+			// A mensagem aprovada chega à audiência como uma mensagem nova
+			event = Message{
+				Kind:   MessageKindMessageCreated,
+				RoomID: rawRoomID,
+				Value: MessageMessageCreated{
+					ID:          rawID,
+					Message:     message.Message,
+					Attribution: message.Attribution,
+				},
+			}
+		}
+		return enqueueWebhooks(r.Context(), q, event)
+	})
+	if err != nil {
+		if errors.Is(err, errPreconditionFailed) {
+			http.Error(w, "message has changed", http.StatusPreconditionFailed)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "pending message not found", http.StatusNotFound)
+			return
+		}
+
+		slog.Error("failed to moderate message", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(r.Context(), message.Version, ""))
+	sendJSON(w, messageForVersion(apiVersion(r.Context()), message)) // Envia a mensagem atualizada como resposta
+
+	go h.notifyClients(r.Context(), event)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestModeratorToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		header    string
+		websocket bool
+		want      string
+	}{
+		{name: "authorization header", target: "/api/rooms/1", header: "Bearer secret", want: "secret"},
+		{name: "header without bearer", target: "/api/rooms/1", header: "secret"},
+		{name: "query on rest", target: "/api/rooms/1?moderator_token=secret"},
+		{name: "query on websocket", target: "/subscribe/1?moderator_token=secret", websocket: true, want: "secret"},
+		{name: "header on websocket", target: "/subscribe/1?moderator_token=other", header: "Bearer secret", websocket: true, want: "secret"},
+		{name: "nothing", target: "/subscribe/1", websocket: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.websocket {
+				r.Header.Set("Connection", "Upgrade")
+				r.Header.Set("Upgrade", "websocket")
+			}
+			if got := moderatorToken(r); got != tt.want {
+				t.Errorf("moderatorToken = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactModeratorToken(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/subscribe/1?moderator_token=secret", "/subscribe/1?moderator_token=REDACTED"},
+		{"/subscribe/1?after=3&moderator_token=secret", "/subscribe/1?after=3&moderator_token=REDACTED"},
+		{"/api/rooms/1?after=3", "/api/rooms/1?after=3"},
+		{"/api/rooms/1", "/api/rooms/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			var logged, token string
+			handler := redactModeratorToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logged, token = r.RequestURI, r.URL.Query().Get(moderatorTokenParam)
+			}))
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if logged != tt.want {
+				t.Errorf("RequestURI = %q, want %q", logged, tt.want)
+			}
+			if want := r.URL.Query().Get(moderatorTokenParam); token != want {
+				t.Errorf("handler got token %q, want %q", token, want)
+			}
+		})
+	}
+}
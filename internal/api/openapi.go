@@ -0,0 +1,70 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// openAPISpec é a especificação OpenAPI 3 da API, incluindo os eventos de WebSocket na extensão x-websocket-events.
+// Ao alterar uma rota ou o formato de uma resposta, atualize também este documento.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPISpec retorna a especificação OpenAPI servida em /api/openapi.json.
+func OpenAPISpec() []byte {
+	return openAPISpec
+}
+
+// handleGetOpenAPI envia a especificação OpenAPI da API.
+func (h apiHandler) handleGetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPISpec)
+}
+
+// UndocumentedRoutes retorna as rotas registradas no roteador que não estão descritas na especificação OpenAPI,
+// no formato "MÉTODO /caminho".
+func UndocumentedRoutes(routes chi.Routes) ([]string, error) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		return nil, err
+	}
+
+	// /api é um alias de /api/v1, então as duas formas são comparadas sem a versão
+	documented := make(map[string]map[string]json.RawMessage, len(spec.Paths))
+	for path, operations := range spec.Paths {
+		documented[unversionedRoute(path)] = operations
+	}
+
+	var missing []string
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// O chi registra "/" dentro de um grupo como um caminho com barra final
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		if _, ok := documented[unversionedRoute(route)][strings.ToLower(method)]; !ok {
+			missing = append(missing, method+" "+route)
+		}
+		return nil
+	})
+	return missing, err
+}
+
+// checkOpenAPI registra no log as rotas que ainda não foram documentadas.
+func checkOpenAPI(routes chi.Routes) {
+	missing, err := UndocumentedRoutes(routes)
+	if err != nil {
+		slog.Error("failed to check openapi spec", "error", err)
+		return
+	}
+	for _, route := range missing {
+		slog.Warn("route missing from openapi spec", "route", route)
+	}
+}
@@ -0,0 +1,577 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/config"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+	"github.com/joao-ressel/go-server/internal/store/pgstore/pgstoretest"
+)
+
+// openAPIChecker envia requisições ao handler e verifica cada resposta contra a especificação OpenAPI:
+// o status precisa estar documentado na operação e o corpo precisa seguir o esquema do tipo de conteúdo.
+type openAPIChecker struct {
+	t         *testing.T
+	handler   http.Handler
+	paths     map[string]map[string]any
+	schemas   map[string]any
+	responses map[string]any
+	v1        struct {
+		Schemas map[string]string `json:"schemas"`
+		Events  []string          `json:"events"`
+	}
+}
+
+// with retorna uma cópia do verificador que reporta as falhas em t, como as de um subteste.
+func (c *openAPIChecker) with(t *testing.T) *openAPIChecker {
+	copied := *c
+	copied.t = t
+	return &copied
+}
+
+func newOpenAPIChecker(t *testing.T, handler http.Handler) *openAPIChecker {
+	t.Helper()
+
+	var spec struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas   map[string]any `json:"schemas"`
+			Responses map[string]any `json:"responses"`
+		} `json:"components"`
+		V1 json.RawMessage `json:"x-api-v1"`
+	}
+	if err := json.Unmarshal(OpenAPISpec(), &spec); err != nil {
+		t.Fatalf("decode openapi spec: %v", err)
+	}
+
+	c := &openAPIChecker{t: t, handler: handler, paths: spec.Paths, schemas: spec.Components.Schemas, responses: spec.Components.Responses}
+	if err := json.Unmarshal(spec.V1, &c.v1); err != nil {
+		t.Fatalf("decode x-api-v1: %v", err)
+	}
+	return c
+}
+
+// request envia a requisição e verifica a resposta. route é a rota da especificação e path o caminho requisitado,
+// ambos sem a versão ("/api/rooms/{room_id}"); as rotas da API são enviadas a /api/v{version}.
+func (c *openAPIChecker) request(version int, method, route, path string, body any, header http.Header) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	specPath := route
+	if rest, ok := strings.CutPrefix(route, "/api/"); ok && route != "/api/openapi.json" {
+		specPath = "/api/v2/" + rest
+		path = fmt.Sprintf("/api/v%d%s", version, strings.TrimPrefix(path, "/api"))
+	}
+
+	var reader *bytes.Reader
+	if raw, ok := body.(string); ok {
+		reader = bytes.NewReader([]byte(raw))
+	} else {
+		data, _ := json.Marshal(body)
+		if body == nil {
+			data = nil
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+
+	operation, ok := c.paths[specPath][strings.ToLower(method)].(map[string]any)
+	if !ok {
+		c.t.Fatalf("%s %s: operation missing from openapi spec", method, specPath)
+	}
+	c.checkResponse(version, method+" "+path, operation, rec)
+	return rec
+}
+
+// ok envia a requisição, exige o status 200 e decodifica o corpo JSON em out, se não for nil.
+func (c *openAPIChecker) ok(version int, method, route, path string, body any, header http.Header, out any) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	rec := c.request(version, method, route, path, body, header)
+	if rec.Code != http.StatusOK {
+		c.t.Fatalf("%s %s: status %d, want 200: %s", method, path, rec.Code, rec.Body.String())
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			c.t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+	return rec
+}
+
+// status envia a requisição e exige o status informado.
+func (c *openAPIChecker) status(want, version int, method, route, path string, body any, header http.Header) {
+	c.t.Helper()
+
+	if rec := c.request(version, method, route, path, body, header); rec.Code != want {
+		c.t.Errorf("%s %s: status %d, want %d: %s", method, path, rec.Code, want, rec.Body.String())
+	}
+}
+
+func (c *openAPIChecker) checkResponse(version int, name string, operation map[string]any, rec *httptest.ResponseRecorder) {
+	c.t.Helper()
+
+	responses, _ := operation["responses"].(map[string]any)
+	response, ok := responses[strconv.Itoa(rec.Code)].(map[string]any)
+	if !ok {
+		c.t.Errorf("%s: status %d is not documented: %s", name, rec.Code, rec.Body.String())
+		return
+	}
+	if ref, ok := response["$ref"].(string); ok {
+		response, _ = c.responses[strings.TrimPrefix(ref, "#/components/responses/")].(map[string]any)
+	}
+
+	content, _ := response["content"].(map[string]any)
+	if len(content) == 0 {
+		if rec.Body.Len() > 0 && rec.Code != http.StatusOK {
+			c.t.Errorf("%s: status %d documents no body, got %q", name, rec.Code, rec.Body.String())
+		}
+		return
+	}
+
+	mediaType, _, _ := strings.Cut(rec.Header().Get("Content-Type"), ";")
+	media, ok := content[strings.TrimSpace(mediaType)].(map[string]any)
+	if !ok {
+		c.t.Errorf("%s: content type %q is not documented for status %d", name, mediaType, rec.Code)
+		return
+	}
+	if mediaType != "application/json" {
+		return
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(rec.Body.Bytes()))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		c.t.Errorf("%s: invalid json: %v", name, err)
+		return
+	}
+	for _, problem := range c.validate(version, media["schema"], value, "body") {
+		c.t.Errorf("%s: %s", name, problem)
+	}
+}
+
+// validate verifica value contra o subconjunto de JSON Schema usado pela especificação.
+// Na v1, os esquemas listados em x-api-v1 substituem os da versão atual.
+func (c *openAPIChecker) validate(version int, rawSchema, value any, at string) []string {
+	schema, _ := rawSchema.(map[string]any)
+
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if replacement, ok := c.v1.Schemas[name]; ok && version == APIVersion1 {
+			name = replacement
+		}
+		target, ok := c.schemas[name]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown schema %s", at, name)}
+		}
+		return c.validate(version, target, value, at)
+	}
+
+	var problems []string
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			problems = append(problems, c.validate(version, sub, value, at)...)
+		}
+	}
+	if one, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, sub := range one {
+			if len(c.validate(version, sub, value, at)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			problems = append(problems, fmt.Sprintf("%s: matches %d schemas of oneOf", at, matches))
+		}
+	}
+	if want, ok := schema["const"]; ok && fmt.Sprint(want) != fmt.Sprint(value) {
+		problems = append(problems, fmt.Sprintf("%s: %v, want %v", at, value, want))
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(v any) bool { return fmt.Sprint(v) == fmt.Sprint(value) }) {
+		problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+	}
+
+	if rawType, ok := schema["type"]; ok {
+		types, ok := rawType.([]any)
+		if !ok {
+			types = []any{rawType}
+		}
+		if !slices.ContainsFunc(types, func(t any) bool { return jsonTypeMatches(t.(string), value) }) {
+			return append(problems, fmt.Sprintf("%s: %T does not match type %v", at, value, rawType))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		switch schema["format"] {
+		case "uuid":
+			if _, err := uuid.Parse(v); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q is not a uuid", at, v))
+			}
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q is not a date-time", at, v))
+			}
+		}
+		if max, ok := schema["maxLength"].(float64); ok && utf8.RuneCountInString(v) > int(max) {
+			problems = append(problems, fmt.Sprintf("%s: longer than %v", at, max))
+		}
+
+	case map[string]any:
+		for _, name := range asStrings(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %s", at, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, property := range v {
+			sub, ok := properties[name]
+			if !ok {
+				if schema["additionalProperties"] == false {
+					problems = append(problems, fmt.Sprintf("%s: unexpected property %s", at, name))
+				}
+				continue
+			}
+			problems = append(problems, c.validate(version, sub, property, at+"."+name)...)
+		}
+
+	case []any:
+		if items, ok := schema["items"]; ok {
+			for i, item := range v {
+				problems = append(problems, c.validate(version, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	}
+	return problems
+}
+
+// jsonTypeMatches indica se o valor decodificado (com json.Decoder.UseNumber) é do tipo JSON Schema informado.
+func jsonTypeMatches(typ string, value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	case json.Number:
+		if typ == "integer" {
+			_, err := v.Int64()
+			return err == nil
+		}
+		return typ == "number"
+	case map[string]any:
+		return typ == "object"
+	case []any:
+		return typ == "array"
+	}
+	return false
+}
+
+func asStrings(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		out = append(out, fmt.Sprint(item))
+	}
+	return out
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	missing, err := UndocumentedRoutes(NewHandler(nil).(apiHandler).r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) > 0 {
+		t.Errorf("routes missing from openapi spec: %v", missing)
+	}
+}
+
+// TestOpenAPIResponsesWithoutDatabase cobre as respostas que não consultam o banco de dados.
+func TestOpenAPIResponsesWithoutDatabase(t *testing.T) {
+	c := newOpenAPIChecker(t, NewHandler(nil))
+
+	c.ok(APIVersion2, http.MethodGet, "/healthz", "/healthz", nil, nil, nil)
+	c.ok(APIVersion2, http.MethodGet, "/readyz", "/readyz", nil, nil, nil)
+	c.ok(APIVersion2, http.MethodGet, "/metrics", "/metrics", nil, nil, nil)
+	c.ok(APIVersion2, http.MethodGet, "/api/openapi.json", "/api/openapi.json", nil, nil, nil)
+
+	for _, version := range supportedAPIVersions {
+		c.status(http.StatusBadRequest, version, http.MethodGet, "/api/rooms/{room_id}", "/api/rooms/not-a-uuid", nil, nil)
+		c.status(http.StatusBadRequest, version, http.MethodGet, "/api/rooms/{room_id}/messages", "/api/rooms/not-a-uuid/messages", nil, nil)
+		c.status(http.StatusBadRequest, version, http.MethodPost, "/api/rooms/{room_id}/messages", "/api/rooms/not-a-uuid/messages", map[string]string{"message": "hi"}, nil)
+		c.status(http.StatusBadRequest, version, http.MethodGet, "/api/rooms/{room_id}/export", "/api/rooms/not-a-uuid/export", nil, nil)
+	}
+	c.status(http.StatusBadRequest, APIVersion2, http.MethodPost, "/api/rooms", "/api/rooms", "{", nil)
+}
+
+// TestOpenAPIResponses percorre as operações da API com o banco de dados em memória de pgstoretest,
+// nas duas versões, verificando cada resposta contra a especificação.
+func TestOpenAPIResponses(t *testing.T) {
+	mem := pgstoretest.NewMemory()
+	checkOpenAPIResponses(t, mem, mem)
+}
+
+// TestOpenAPIResponsesPostgres repete TestOpenAPIResponses com um banco de dados real (veja pgstoretest.EnvDatabaseURL).
+func TestOpenAPIResponsesPostgres(t *testing.T) {
+	pool := pgstoretest.New(t)
+	checkOpenAPIResponses(t, pgstore.New(pool), pool)
+}
+
+func checkOpenAPIResponses(t *testing.T, q pgstore.Querier, db DB) {
+	handler := NewHandler(q,
+		WithDatabase(db),
+		WithRateLimit(config.RateLimitConfig{Default: config.RateLimit{Rate: 1000, Burst: 1000}}),
+	)
+	c := newOpenAPIChecker(t, handler)
+	v1, v2 := APIVersion1, APIVersion2
+	get, post, patch, put, del := http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodPut, http.MethodDelete
+
+	// Os passos compartilham a sala e as mensagens criadas pelos anteriores e são executados em ordem;
+	// a falha de um passo interrompe os seguintes
+	var (
+		created struct {
+			ID             string `json:"id"`
+			ModeratorToken string `json:"moderator_token"`
+		}
+		first, second struct {
+			ID string `json:"id"`
+		}
+		room, message, other string
+		mod, wrong           http.Header
+		export               json.RawMessage
+	)
+	steps := []struct {
+		name string
+		run  func(t *testing.T, c *openAPIChecker)
+	}{
+		{"readyz", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, get, "/readyz", "/readyz", nil, nil, nil)
+		}},
+		{"create room", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, post, "/api/rooms", "/api/rooms", map[string]any{"theme": "OpenAPI"}, nil, &created)
+			room = "/api/rooms/" + created.ID
+			mod = http.Header{"Authorization": {"Bearer " + created.ModeratorToken}}
+			wrong = http.Header{"Authorization": {"Bearer wrong"}}
+		}},
+		{"room ETags per version", func(t *testing.T, c *openAPIChecker) {
+			etags := make(map[string]int)
+			for _, version := range supportedAPIVersions {
+				c.ok(version, get, "/api/rooms", "/api/rooms", nil, nil, nil)
+				rec := c.ok(version, get, "/api/rooms/{room_id}", room, nil, nil, nil)
+				c.status(http.StatusNotModified, version, get, "/api/rooms/{room_id}", room, nil, http.Header{"If-None-Match": {rec.Header().Get("ETag")}})
+				etags[rec.Header().Get("ETag")] = version
+			}
+			if len(etags) != len(supportedAPIVersions) {
+				t.Errorf("API versions share room ETags: %v", etags)
+			}
+			for etag, version := range etags {
+				if version != v1 {
+					c.status(http.StatusOK, v1, get, "/api/rooms/{room_id}", room, nil, http.Header{"If-None-Match": {etag}})
+				}
+			}
+		}},
+		{"room settings require the moderator token", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, patch, "/api/rooms/{room_id}/settings", room+"/settings", map[string]any{"moderated": false}, mod, nil)
+			c.status(http.StatusUnauthorized, v2, patch, "/api/rooms/{room_id}/settings", room+"/settings", map[string]any{"moderated": false}, nil)
+			c.status(http.StatusForbidden, v2, patch, "/api/rooms/{room_id}/settings", room+"/settings", map[string]any{"moderated": false}, wrong)
+		}},
+		{"create messages", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, post, "/api/rooms/{room_id}/messages", room+"/messages", map[string]any{"message": "First question"}, nil, &first)
+			c.ok(v1, post, "/api/rooms/{room_id}/messages", room+"/messages", map[string]any{"message": "Second question"}, nil, &second)
+			message = room + "/messages/" + first.ID
+			other = room + "/messages/" + second.ID
+		}},
+		{"get and react to messages", func(t *testing.T, c *openAPIChecker) {
+			for _, version := range supportedAPIVersions {
+				c.ok(version, get, "/api/rooms/{room_id}/messages", room+"/messages", nil, nil, nil)
+				c.ok(version, get, "/api/rooms/{room_id}/messages", room+"/messages", nil, mod, nil)
+				rec := c.ok(version, get, "/api/rooms/{room_id}/messages/{message_id}", message, nil, nil, nil)
+				c.status(http.StatusNotModified, version, get, "/api/rooms/{room_id}/messages/{message_id}", message, nil, http.Header{"If-None-Match": {rec.Header().Get("ETag")}})
+				c.ok(version, patch, "/api/rooms/{room_id}/messages/{message_id}/react", message+"/react", nil, nil, nil)
+			}
+			c.ok(v2, del, "/api/rooms/{room_id}/messages/{message_id}/react", message+"/react", nil, nil, nil)
+			c.status(http.StatusPreconditionFailed, v2, patch, "/api/rooms/{room_id}/messages/{message_id}/react", message+"/react", nil, http.Header{"If-Match": {`"0"`}})
+		}},
+		{"now answering follows the message status", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, patch, "/api/rooms/{room_id}/messages/{message_id}/status", message+"/status", map[string]any{"status": MessageStatusAnswering}, mod, nil)
+			c.ok(v2, put, "/api/rooms/{room_id}/now-answering", room+"/now-answering", map[string]any{"message_id": first.ID}, mod, nil)
+
+			// O estado "answering" e o destaque da sala andam juntos: a mensagem que passa a ser respondida
+			// entra em destaque e a anterior volta para "open"
+			var answering struct {
+				NowAnsweringID *string `json:"now_answering_id"`
+			}
+			var status struct {
+				Status string `json:"status"`
+			}
+			c.ok(v2, put, "/api/rooms/{room_id}/now-answering", room+"/now-answering", map[string]any{"message_id": second.ID}, mod, &answering)
+			if answering.NowAnsweringID == nil || *answering.NowAnsweringID != second.ID {
+				t.Errorf("now_answering_id = %v, want %s", answering.NowAnsweringID, second.ID)
+			}
+			if c.ok(v2, get, "/api/rooms/{room_id}/messages/{message_id}", message, nil, nil, &status); status.Status != MessageStatusOpen {
+				t.Errorf("previous message status = %s, want %s", status.Status, MessageStatusOpen)
+			}
+			if c.ok(v2, get, "/api/rooms/{room_id}/messages/{message_id}", other, nil, nil, &status); status.Status != MessageStatusAnswering {
+				t.Errorf("now answering message status = %s, want %s", status.Status, MessageStatusAnswering)
+			}
+			c.ok(v2, patch, "/api/rooms/{room_id}/messages/{message_id}/status", other+"/status", map[string]any{"status": MessageStatusDismissed}, mod, nil)
+			if c.ok(v2, get, "/api/rooms/{room_id}", room, nil, nil, &answering); answering.NowAnsweringID != nil {
+				t.Errorf("now_answering_id = %s after leaving answering, want null", *answering.NowAnsweringID)
+			}
+			c.ok(v2, patch, "/api/rooms/{room_id}/messages/{message_id}/status", other+"/status", map[string]any{"status": MessageStatusOpen}, mod, nil)
+
+			c.ok(v2, put, "/api/rooms/{room_id}/now-answering", room+"/now-answering", map[string]any{"message_id": first.ID}, mod, nil)
+			c.ok(v1, del, "/api/rooms/{room_id}/now-answering", room+"/now-answering", nil, mod, nil)
+			if c.ok(v2, get, "/api/rooms/{room_id}/messages/{message_id}", message, nil, nil, &status); status.Status != MessageStatusOpen {
+				t.Errorf("message status = %s after clearing now answering, want %s", status.Status, MessageStatusOpen)
+			}
+		}},
+		{"answer requires the moderator token", func(t *testing.T, c *openAPIChecker) {
+			c.status(http.StatusUnauthorized, v2, patch, "/api/rooms/{room_id}/messages/{message_id}/answer", message+"/answer", nil, nil)
+			c.status(http.StatusForbidden, v2, patch, "/api/rooms/{room_id}/messages/{message_id}/answer", message+"/answer", nil, wrong)
+			c.ok(v2, patch, "/api/rooms/{room_id}/messages/{message_id}/answer", message+"/answer", nil, mod, nil)
+			c.status(http.StatusConflict, v2, patch, "/api/rooms/{room_id}/messages/{message_id}/status", message+"/status", map[string]any{"status": MessageStatusDuplicate}, mod)
+			c.ok(v1, patch, "/api/rooms/{room_id}/messages/{message_id}/status", message+"/status", map[string]any{"status": MessageStatusOpen}, mod, nil)
+		}},
+		{"pin messages", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, patch, "/api/rooms/{room_id}/messages/{message_id}/pin", other+"/pin", nil, mod, nil)
+			c.ok(v2, get, "/api/rooms/{room_id}", room, nil, nil, nil)
+			c.ok(v1, del, "/api/rooms/{room_id}/messages/{message_id}/pin", other+"/pin", nil, mod, nil)
+		}},
+		{"report messages", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, post, "/api/rooms/{room_id}/messages/{message_id}/report", other+"/report", map[string]any{"reason": "spam"}, nil, nil)
+			c.status(http.StatusNotFound, v2, post, "/api/rooms/{room_id}/messages/{message_id}/report", room+"/messages/"+uuid.NewString()+"/report", map[string]any{}, nil)
+			c.ok(v2, get, "/api/rooms/{room_id}/reports", room+"/reports", nil, mod, nil)
+		}},
+		{"moderation queue", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v1, patch, "/api/rooms/{room_id}/settings", room+"/settings", map[string]any{"moderated": true}, mod, nil)
+			var pending, rejected struct {
+				ID string `json:"id"`
+			}
+			c.ok(v2, post, "/api/rooms/{room_id}/messages", room+"/messages", map[string]any{"message": "Pending question"}, nil, &pending)
+			c.ok(v2, post, "/api/rooms/{room_id}/messages", room+"/messages", map[string]any{"message": "Rejected question"}, nil, &rejected)
+			c.ok(v1, patch, "/api/rooms/{room_id}/messages/{message_id}/approve", room+"/messages/"+pending.ID+"/approve", nil, mod, nil)
+			c.ok(v2, patch, "/api/rooms/{room_id}/messages/{message_id}/reject", room+"/messages/"+rejected.ID+"/reject", nil, mod, nil)
+			c.status(http.StatusNotFound, v2, patch, "/api/rooms/{room_id}/messages/{message_id}/reject", room+"/messages/"+rejected.ID+"/reject", nil, mod)
+		}},
+		{"participants and bans", func(t *testing.T, c *openAPIChecker) {
+			var participant struct {
+				ParticipantID string `json:"participant_id"`
+				Token         string `json:"token"`
+			}
+			c.ok(v2, post, "/api/participants", "/api/participants", nil, nil, &participant)
+			muted := http.Header{"X-Participant-Token": {participant.Token}}
+			forged := http.Header{"X-Participant-Token": {participant.ParticipantID + ".forged"}}
+
+			var ban struct {
+				ID string `json:"id"`
+			}
+			c.status(http.StatusBadRequest, v2, post, "/api/rooms/{room_id}/bans", room+"/bans", map[string]any{"participant_id": "participant-1", "kind": BanKindMute}, mod)
+			c.status(http.StatusBadRequest, v2, post, "/api/rooms/{room_id}/bans", room+"/bans", map[string]any{"participant_id": participant.ParticipantID, "kind": BanKindMute, "reason": strings.Repeat("é", 256)}, mod)
+			c.ok(v2, post, "/api/rooms/{room_id}/bans", room+"/bans", map[string]any{"participant_id": participant.ParticipantID, "kind": BanKindMute}, mod, &ban)
+			c.status(http.StatusForbidden, v2, post, "/api/rooms/{room_id}/messages", room+"/messages", map[string]any{"message": "Muted question"}, muted)
+			c.ok(v2, post, "/api/rooms/{room_id}/messages", room+"/messages", map[string]any{"message": "Anonymous question"}, forged, nil)
+			c.ok(v2, get, "/api/rooms/{room_id}/bans", room+"/bans", nil, mod, nil)
+			c.ok(v2, del, "/api/rooms/{room_id}/bans/{ban_id}", room+"/bans/"+ban.ID, nil, mod, nil)
+			c.status(http.StatusNotFound, v2, del, "/api/rooms/{room_id}/bans/{ban_id}", room+"/bans/"+ban.ID, nil, mod)
+		}},
+		{"filter rules", func(t *testing.T, c *openAPIChecker) {
+			var rule struct {
+				ID string `json:"id"`
+			}
+			c.ok(v2, post, "/api/rooms/{room_id}/filters", room+"/filters", map[string]any{"pattern": "spam", "action": "reject"}, mod, &rule)
+			c.ok(v2, get, "/api/rooms/{room_id}/filters", room+"/filters", nil, mod, nil)
+			c.ok(v2, del, "/api/rooms/{room_id}/filters/{rule_id}", room+"/filters/"+rule.ID, nil, mod, nil)
+		}},
+		{"api keys", func(t *testing.T, c *openAPIChecker) {
+			var key struct {
+				ID string `json:"id"`
+			}
+			c.ok(v2, post, "/api/rooms/{room_id}/api-keys", room+"/api-keys", map[string]any{"name": "bot"}, mod, &key)
+			c.ok(v2, get, "/api/rooms/{room_id}/api-keys", room+"/api-keys", nil, mod, nil)
+			c.ok(v2, del, "/api/rooms/{room_id}/api-keys/{key_id}", room+"/api-keys/"+key.ID, nil, mod, nil)
+		}},
+		{"webhooks", func(t *testing.T, c *openAPIChecker) {
+			var webhook struct {
+				ID string `json:"id"`
+			}
+			c.ok(v2, post, "/api/rooms/{room_id}/webhooks", room+"/webhooks", map[string]any{"url": "https://example.com/hook"}, mod, &webhook)
+			c.ok(v2, get, "/api/rooms/{room_id}/webhooks", room+"/webhooks", nil, mod, nil)
+			// As entregas são gravadas com a alteração que produziu o evento, antes da resposta
+			var deliveries []struct {
+				EventKind string `json:"event_kind"`
+			}
+			c.ok(v2, patch, "/api/rooms/{room_id}/messages/{message_id}/react", message+"/react", nil, nil, nil)
+			c.ok(v2, get, "/api/rooms/{room_id}/webhooks/deliveries", room+"/webhooks/deliveries", nil, mod, &deliveries)
+			if len(deliveries) != 1 || deliveries[0].EventKind != MessageKindMessageRactionIncreased {
+				t.Errorf("webhook deliveries = %+v, want one %s", deliveries, MessageKindMessageRactionIncreased)
+			}
+			c.ok(v2, del, "/api/rooms/{room_id}/webhooks/{webhook_id}", room+"/webhooks/"+webhook.ID, nil, mod, nil)
+		}},
+		{"export and feeds", func(t *testing.T, c *openAPIChecker) {
+			for _, version := range supportedAPIVersions {
+				c.ok(version, get, "/api/rooms/{room_id}/export", room+"/export", nil, mod, &export)
+				c.ok(version, get, "/api/rooms/{room_id}/export", room+"/export?format=csv", nil, nil, nil)
+				c.ok(version, get, "/api/rooms/{room_id}/export", room+"/export?format=md", nil, nil, nil)
+				rec := c.ok(version, get, "/api/rooms/{room_id}/feed.atom", room+"/feed.atom", nil, nil, nil)
+				c.status(http.StatusNotModified, version, get, "/api/rooms/{room_id}/feed.atom", room+"/feed.atom", nil, http.Header{"If-None-Match": {rec.Header().Get("ETag")}})
+				// Last-Modified só é enviado um segundo depois da última alteração; o comportamento é coberto por TestFeedLastModified
+				if lastModified := rec.Header().Get("Last-Modified"); lastModified != "" {
+					c.status(http.StatusNotModified, version, get, "/api/rooms/{room_id}/feed.atom", room+"/feed.atom", nil, http.Header{"If-Modified-Since": {lastModified}})
+				}
+				c.ok(version, get, "/api/rooms/{room_id}/feed.atom", room+"/feed.atom", nil, http.Header{"If-Modified-Since": {time.Unix(0, 0).Format(http.TimeFormat)}}, nil)
+				c.ok(version, get, "/api/rooms/{room_id}/feed.rss", room+"/feed.rss?answered=true", nil, nil, nil)
+			}
+			c.status(http.StatusBadRequest, v2, get, "/api/rooms/{room_id}/export", room+"/export?format=xml", nil, nil)
+		}},
+		{"import and clone", func(t *testing.T, c *openAPIChecker) {
+			c.ok(v2, post, "/api/rooms/import", "/api/rooms/import", string(export), nil, nil)
+			c.ok(v2, post, "/api/rooms/{room_id}/clone", room+"/clone", map[string]any{"carry_over_unanswered": true}, mod, nil)
+		}},
+		{"poll events per version", func(t *testing.T, c *openAPIChecker) {
+			// Os eventos são publicados em segundo plano; a consulta aguarda até que o primeiro deles chegue
+			for _, version := range supportedAPIVersions {
+				var poll struct {
+					Events []struct {
+						Kind string `json:"kind"`
+					} `json:"events"`
+				}
+				c.ok(version, get, "/api/rooms/{room_id}/poll", room+"/poll?since=0&timeout=5s", nil, mod, &poll)
+				if len(poll.Events) == 0 {
+					t.Errorf("v%d poll: no events", version)
+				}
+				for _, event := range poll.Events {
+					if version == APIVersion1 && !slices.Contains(c.v1.Events, event.Kind) {
+						t.Errorf("v1 poll: event %s is not part of v1", event.Kind)
+					}
+					if version != APIVersion1 && slices.Contains(eventKindsV1Only, event.Kind) {
+						t.Errorf("v%d poll: event %s is only part of v1", version, event.Kind)
+					}
+				}
+			}
+		}},
+	}
+	for _, step := range steps {
+		if !t.Run(step.name, func(t *testing.T) { step.run(t, c.with(t)) }) {
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joao-ressel/go-server/internal/config"
+)
+
+// WithAllowedOrigins define as origens aceitas pelo CORS e pelo upgrade de WebSocket, no formato de config.ParseOrigins.
+// Padrões inválidos são ignorados; use config.ValidateOrigins para verificá-los antes.
+func WithAllowedOrigins(patterns []string) Option {
+	return func(h *apiHandler) {
+		list, err := config.ParseOrigins(patterns)
+		if err != nil {
+			slog.Error("ignoring invalid allowed origins", "error", err)
+			return
+		}
+		h.origins = list
+	}
+}
+
+// allowCORSOrigin é usada pelo middleware de CORS para decidir se a origem recebe os cabeçalhos de acesso.
+func (h apiHandler) allowCORSOrigin(r *http.Request, origin string) bool {
+	return h.origins.Allowed(origin)
+}
+
+// checkWebSocketOrigin verifica o cabeçalho Origin do upgrade de WebSocket.
+// Requisições sem Origin não vêm de navegadores e são aceitas, assim como as da mesma origem do servidor.
+// Upgrades recusados são registrados no log.
+func (h apiHandler) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || h.origins.Allowed(origin) {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	slog.Warn("rejected websocket upgrade from disallowed origin",
+		"origin", origin,
+		"room_id", chi.URLParam(r, "room_id"),
+		"ip", clientIP(r),
+	)
+	return false
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// participantTokenHeader é o cabeçalho com o token de participante emitido por POST /participants.
+const participantTokenHeader = "X-Participant-Token"
+
+// WithParticipantKey define a chave que assina os tokens de participante.
+// Instâncias que atendem os mesmos clientes devem usar a mesma chave; sem ela, é usada uma chave aleatória
+// e os tokens emitidos deixam de valer quando o servidor reinicia.
+func WithParticipantKey(key []byte) Option {
+	return func(h *apiHandler) {
+		h.participantKey = key
+	}
+}
+
+// newParticipantKey gera a chave aleatória usada quando WithParticipantKey não é informada.
+func newParticipantKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("failed to generate participant key: " + err.Error())
+	}
+	return key
+}
+
+// signParticipant retorna a assinatura do ID de participante com a chave do servidor.
+func signParticipant(key []byte, id string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newParticipantToken emite uma identidade de participante: o ID e o token "ID.assinatura" enviado pelo cliente.
+func newParticipantToken(key []byte) (id, token string) {
+	id = uuid.NewString()
+	return id, id + "." + signParticipant(key, id)
+}
+
+// verifyParticipantToken retorna o ID de participante do token se a assinatura for válida.
+func verifyParticipantToken(key []byte, token string) (string, bool) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(signParticipant(key, id))) {
+		return "", false
+	}
+	return id, true
+}
+
+// participantID obtém o ID do participante a partir do token enviado no cabeçalho X-Participant-Token.
+// Como navegadores não permitem cabeçalhos personalizados em conexões WebSocket, o parâmetro participant_token também é aceito.
+// O ID é emitido pelo servidor, e não declarado pelo cliente, para que banimentos e limites por participante não sejam
+// contornados trocando o identificador. Retorna uma string vazia se não houver token ou se ele não for válido.
+func (h apiHandler) participantID(r *http.Request) string {
+	token := r.Header.Get(participantTokenHeader)
+	if token == "" {
+		token = r.URL.Query().Get("participant_token")
+	}
+	if token == "" {
+		return ""
+	}
+
+	id, ok := verifyParticipantToken(h.participantKey, token)
+	if !ok {
+		return ""
+	}
+	return id
+}
+
+// handleCreateParticipant emite uma nova identidade de participante.
+// O token deve ser guardado pelo cliente e enviado nas requisições seguintes; o ID é o usado nos banimentos.
+func (h apiHandler) handleCreateParticipant(w http.ResponseWriter, r *http.Request) {
+	id, token := newParticipantToken(h.participantKey)
+
+	type response struct {
+		ParticipantID string `json:"participant_id"`
+		Token         string `json:"token"`
+	}
+
+	sendJSON(w, response{ParticipantID: id, Token: token}) // Envia a identidade emitida como resposta
+}
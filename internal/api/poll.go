@@ -0,0 +1,207 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limites do tempo de espera do long polling (GET /poll)
+const (
+	defaultPollTimeout = 30 * time.Second
+	maxPollTimeout     = 50 * time.Second // Abaixo do prazo padrão de escrita das respostas (http.write_timeout)
+)
+
+// maxRoomEvents é o número de eventos recentes guardados por sala para os clientes de long polling.
+const maxRoomEvents = 256
+
+// roomEvent é um evento enviado aos assinantes de uma sala, com o seu número de sequência.
+type roomEvent struct {
+	seq uint64
+	msg Message
+}
+
+// roomEvents guarda os eventos recentes de uma sala.
+type roomEvents struct {
+	seq     uint64        // Sequência do último evento da sala
+	events  []roomEvent   // Até maxRoomEvents eventos, do mais antigo ao mais recente
+	changed chan struct{} // Fechado, e substituído, a cada evento novo
+	last    time.Time     // Momento do último evento
+}
+
+// eventLog guarda os eventos recentes de cada sala para os clientes que não usam WebSocket.
+// As sequências começam em zero a cada início do servidor e são próprias de cada instância, assim como os assinantes.
+type eventLog struct {
+	rooms     map[string]*roomEvents
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{
+		rooms:     make(map[string]*roomEvents),
+		lastSweep: time.Now(),
+	}
+}
+
+// room retorna os eventos da sala, criando o registro se ele ainda não existir. Deve ser chamado com l.mu travado.
+func (l *eventLog) room(roomID string) *roomEvents {
+	room, ok := l.rooms[roomID]
+	if !ok {
+		room = &roomEvents{changed: make(chan struct{})}
+		l.rooms[roomID] = room
+	}
+	return room
+}
+
+// append registra um evento da sala e acorda os clientes que aguardam eventos novos.
+func (l *eventLog) append(msg Message) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	room := l.room(msg.RoomID)
+	room.seq++
+	room.events = append(room.events, roomEvent{seq: room.seq, msg: msg})
+	if len(room.events) > maxRoomEvents {
+		room.events = room.events[len(room.events)-maxRoomEvents:]
+	}
+	room.last = now
+
+	close(room.changed)
+	room.changed = make(chan struct{})
+}
+
+// since retorna os eventos da sala posteriores a seq e a sequência do último evento.
+// truncated indica que eventos posteriores a seq já foram descartados, ou que seq é de outra instância ou de antes
+// de um reinício; nesse caso o cliente deve recarregar a sala. changed é fechado quando chegar um evento novo.
+func (l *eventLog) since(roomID string, seq uint64) (events []Message, last uint64, truncated bool, changed <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	room := l.room(roomID)
+	if seq > room.seq {
+		return nil, room.seq, true, room.changed
+	}
+
+	first := room.seq - uint64(len(room.events)) + 1 // Sequência do evento mais antigo guardado
+	truncated = seq+1 < first
+
+	for _, e := range room.events {
+		if e.seq > seq {
+			events = append(events, e.msg)
+		}
+	}
+	return events, room.seq, truncated, room.changed
+}
+
+// wakeAll acorda todos os clientes em espera, para que respondam durante o encerramento do servidor.
+func (l *eventLog) wakeAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, room := range l.rooms {
+		close(room.changed)
+		room.changed = make(chan struct{})
+	}
+}
+
+// sweep descarta os eventos das salas sem eventos novos há mais de 10 minutos.
+// A sequência da sala é mantida, para que os clientes em dia não precisem recarregá-la. Deve ser chamado com l.mu travado.
+func (l *eventLog) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for _, room := range l.rooms {
+		if now.Sub(room.last) > 10*time.Minute {
+			room.events = nil
+		}
+	}
+}
+
+// handlePollRoom aguarda eventos da sala para clientes que não podem usar WebSocket.
+// Responde assim que houver eventos posteriores a since, ou vazio quando timeout expirar (padrão 30s, máximo 50s).
+// Sem since, responde imediatamente com a sequência atual, que o cliente usa na consulta seguinte.
+// Os eventos têm o mesmo formato dos enviados via WebSocket; os da fila de moderação são enviados apenas aos moderadores.
+func (h apiHandler) handlePollRoom(w http.ResponseWriter, r *http.Request) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if h.draining.Load() { // Durante o encerramento, novas consultas são recusadas
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	if !h.checkBan(w, r, roomID, BanKindBan) { // Participantes banidos não podem acompanhar a sala
+		return
+	}
+
+	var since uint64
+	rawSince := r.URL.Query().Get("since")
+	if rawSince != "" {
+		var err error
+		if since, err = strconv.ParseUint(rawSince, 10, 64); err != nil {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+	}
+
+	timeout := defaultPollTimeout
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			http.Error(w, "invalid timeout", http.StatusBadRequest)
+			return
+		}
+		timeout = min(d, maxPollTimeout)
+	}
+
+	moderator := h.isModerator(r, roomID) // Moderadores recebem também os eventos da fila de moderação
+	version := apiVersion(r.Context())
+
+	type response struct {
+		Seq       uint64    `json:"seq"`       // Sequência do último evento, para a próxima consulta
+		Events    []Message `json:"events"`    // Eventos posteriores a since, do mais antigo ao mais recente
+		Truncated bool      `json:"truncated"` // Eventos foram perdidos; o cliente deve recarregar a sala
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		events, seq, truncated, changed := h.events.since(rawRoomID, since)
+
+		visible := []Message{}
+		for _, msg := range events {
+			if msg.ModeratorsOnly && !moderator {
+				continue // Eventos de moderação não são enviados à audiência
+			}
+			if event, ok := eventForVersion(msg, version); ok {
+				visible = append(visible, event)
+			}
+		}
+
+		if len(visible) > 0 || truncated || rawSince == "" || h.draining.Load() {
+			sendJSON(w, response{Seq: seq, Events: visible, Truncated: truncated})
+			return
+		}
+		since = seq // Os eventos ignorados não são examinados de novo
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			sendJSON(w, response{Seq: seq, Events: visible})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// appendEvents registra n eventos na sala, com os valores 1 a n, em sequência.
+func appendEvents(l *eventLog, roomID string, n int) {
+	for i := range n {
+		l.append(Message{Kind: MessageKindMessageCreated, RoomID: roomID, Value: i + 1})
+	}
+}
+
+// eventValues retorna os valores dos eventos, que appendEvents faz iguais às suas sequências.
+func eventValues(events []Message) []int {
+	var values []int
+	for _, e := range events {
+		values = append(values, e.Value.(int))
+	}
+	return values
+}
+
+// seqRange retorna as sequências de first a last.
+func seqRange(first, last int) []int {
+	var seqs []int
+	for seq := first; seq <= last; seq++ {
+		seqs = append(seqs, seq)
+	}
+	return seqs
+}
+
+func TestEventLogSince(t *testing.T) {
+	tests := []struct {
+		name          string
+		appended      int
+		since         uint64
+		want          []int
+		wantLast      uint64
+		wantTruncated bool
+	}{
+		{"empty room", 0, 0, nil, 0, false},
+		{"all events", 3, 0, []int{1, 2, 3}, 3, false},
+		{"newer events", 3, 1, []int{2, 3}, 3, false},
+		{"up to date", 3, 3, nil, 3, false},
+		{"sequence from the future", 3, 4, nil, 3, true},
+		{"sequence from the future of an empty room", 0, 1, nil, 0, true},
+		{"oldest kept event", maxRoomEvents + 2, 2, seqRange(3, maxRoomEvents+2), maxRoomEvents + 2, false},
+		{"discarded events", maxRoomEvents + 2, 1, seqRange(3, maxRoomEvents+2), maxRoomEvents + 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newEventLog()
+			appendEvents(l, "room", tt.appended)
+
+			events, last, truncated, _ := l.since("room", tt.since)
+			if got := eventValues(events); !slices.Equal(got, tt.want) || last != tt.wantLast || truncated != tt.wantTruncated {
+				t.Errorf("since(%d) = %v, %d, %v, want %v, %d, %v", tt.since, got, last, truncated, tt.want, tt.wantLast, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestEventLogRoomsAreIndependent(t *testing.T) {
+	l := newEventLog()
+	appendEvents(l, "a", 2)
+	appendEvents(l, "b", 1)
+
+	if events, last, _, _ := l.since("b", 0); len(events) != 1 || last != 1 {
+		t.Errorf("room b: %d events up to %d, want 1 up to 1", len(events), last)
+	}
+}
+
+func TestEventLogChanged(t *testing.T) {
+	l := newEventLog()
+	_, _, _, changed := l.since("room", 0)
+
+	select {
+	case <-changed:
+		t.Fatal("changed closed before any event")
+	default:
+	}
+
+	appendEvents(l, "room", 1)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("changed not closed by a new event")
+	}
+
+	// Cada espera recebe um canal novo
+	_, _, _, changed = l.since("room", 1)
+	l.wakeAll()
+	select {
+	case <-changed:
+	default:
+		t.Fatal("changed not closed by wakeAll")
+	}
+}
+
+func TestEventLogSweep(t *testing.T) {
+	l := newEventLog()
+	appendEvents(l, "idle", 2)
+	appendEvents(l, "active", 2)
+
+	// Só as salas sem eventos há mais de 10 minutos perdem os eventos, e as sequências são mantidas
+	now := time.Now().Add(11 * time.Minute)
+	l.rooms["active"].last = now
+	l.sweep(now)
+
+	if events, last, truncated, _ := l.since("idle", 2); len(events) != 0 || last != 2 || truncated {
+		t.Errorf("up-to-date client of a swept room: %d events up to %d, truncated %v, want none up to 2", len(events), last, truncated)
+	}
+	if _, _, truncated, _ := l.since("idle", 1); !truncated {
+		t.Error("client behind a swept room not told to reload")
+	}
+	if events, _, truncated, _ := l.since("active", 0); len(events) != 2 || truncated {
+		t.Errorf("active room: %d events, truncated %v, want 2 events", len(events), truncated)
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/joao-ressel/go-server/internal/config"
+)
+
+// bucket guarda o estado de um balde de tokens individual.
+type bucket struct {
+	tokens float64   // Tokens disponíveis no último acesso
+	last   time.Time // Momento do último acesso
+}
+
+// rateLimiter mantém os baldes de tokens por rota, sala e identidade (participante, IP ou chave de API).
+type rateLimiter struct {
+	cfg       config.RateLimitConfig
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		cfg:       cfg,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// limitFor retorna o limite aplicável à rota e à sala informadas.
+func (l *rateLimiter) limitFor(route, roomID string) config.RateLimit {
+	if limit, ok := l.cfg.Rooms[roomID]; ok && roomID != "" {
+		return limit
+	}
+	if limit, ok := l.cfg.Routes[route]; ok {
+		return limit
+	}
+	return l.cfg.Default
+}
+
+// take consome um token do balde identificado por key.
+// Retorna se a requisição foi permitida, quantos tokens restam e quanto tempo falta para o reabastecimento:
+// até o próximo token quando negada, ou até o balde encher quando permitida.
+func (l *rateLimiter) take(key string, limit config.RateLimit) (allowed bool, remaining int, reset time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	// Repõe os tokens proporcionalmente ao tempo decorrido desde o último acesso
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		if limit.Rate <= 0 {
+			return false, 0, time.Hour
+		}
+		return false, 0, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+
+	b.tokens--
+	if limit.Rate <= 0 {
+		return true, int(b.tokens), 0
+	}
+	return true, int(b.tokens), time.Duration((float64(limit.Burst) - b.tokens) / limit.Rate * float64(time.Second))
+}
+
+// sweep remove baldes que estão ociosos há tempo suficiente para estarem cheios novamente.
+// Deve ser chamado com l.mu travado.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > 10*time.Minute {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimit é o middleware aplicado às rotas de escrita (POST, PATCH e DELETE).
+// Cada requisição consome um token do balde do IP do cliente e, se informado, do balde do participante.
+// Requisições com uma chave de API válida da sala consomem apenas o balde da chave, para que os bots
+// não disputem os tokens com a audiência que compartilha o mesmo IP.
+func (h apiHandler) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + unversionedRoute(chi.RouteContext(r.Context()).RoutePattern()) // /api e /api/vN compartilham os limites
+		roomID := chi.URLParam(r, "room_id")
+		limit := h.limiter.limitFor(route, roomID)
+
+		keys := []string{"ip:" + clientIP(r)}
+		if participant := h.participantID(r); participant != "" {
+			keys = append(keys, "participant:"+participant)
+		}
+		if key, ok := h.lookupAPIKey(r, roomID); ok {
+			keys = []string{"apikey:" + key.ID.String()}
+			r = r.WithContext(context.WithValue(r.Context(), apiKeyKey{}, key)) // Evita autenticar a chave de novo no handler
+		}
+
+		allowed, remaining, reset, retry := true, limit.Burst, time.Duration(0), time.Duration(0)
+		for _, key := range keys {
+			ok, left, wait := h.limiter.take(route+"|"+roomID+"|"+key, limit)
+			// Reporta sempre o balde mais restritivo
+			remaining = min(remaining, left)
+			reset = max(reset, wait)
+			if !ok {
+				allowed = false
+				retry = max(retry, wait)
+			}
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retry.Seconds())))))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP obtém o IP do cliente a partir do endereço remoto da conexão.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
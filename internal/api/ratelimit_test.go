@@ -0,0 +1,129 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joao-ressel/go-server/internal/config"
+)
+
+func TestRateLimiterLimitFor(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Default: config.RateLimit{Rate: 1, Burst: 1},
+		Routes:  map[string]config.RateLimit{"POST /api/rooms/{room_id}/messages": {Rate: 2, Burst: 2}},
+		Rooms:   map[string]config.RateLimit{"room-1": {Rate: 3, Burst: 3}, "": {Rate: 4, Burst: 4}},
+	}
+	tests := []struct {
+		name          string
+		route, roomID string
+		want          config.RateLimit
+	}{
+		{"room overrides route", "POST /api/rooms/{room_id}/messages", "room-1", cfg.Rooms["room-1"]},
+		{"room overrides default", "PATCH /api/rooms/{room_id}/settings", "room-1", cfg.Rooms["room-1"]},
+		{"route", "POST /api/rooms/{room_id}/messages", "room-2", cfg.Routes["POST /api/rooms/{room_id}/messages"]},
+		{"default", "POST /api/rooms", "", cfg.Default},
+		{"routes without a room ignore the empty room key", "POST /api/participants", "", cfg.Default},
+	}
+	l := newRateLimiter(cfg)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.limitFor(tt.route, tt.roomID); got != tt.want {
+				t.Errorf("limitFor(%q, %q) = %+v, want %+v", tt.route, tt.roomID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterTake(t *testing.T) {
+	type step struct {
+		after         time.Duration // Tempo decorrido desde o passo anterior
+		wantAllowed   bool
+		wantRemaining int
+		wantReset     time.Duration
+	}
+	tests := []struct {
+		name  string
+		limit config.RateLimit
+		steps []step
+	}{
+		{"burst then refill", config.RateLimit{Rate: 1, Burst: 2}, []step{
+			{0, true, 1, time.Second},
+			{0, true, 0, 2 * time.Second},
+			{0, false, 0, time.Second},
+			{500 * time.Millisecond, false, 0, 500 * time.Millisecond},
+			{500 * time.Millisecond, true, 0, 2 * time.Second},
+			{time.Minute, true, 1, time.Second}, // O balde não passa da capacidade
+		}},
+		{"fractional rate", config.RateLimit{Rate: 0.5, Burst: 1}, []step{
+			{0, true, 0, 2 * time.Second},
+			{time.Second, false, 0, time.Second},
+			{time.Second, true, 0, 2 * time.Second},
+		}},
+		{"no refill", config.RateLimit{Rate: 0, Burst: 1}, []step{
+			{0, true, 0, 0},
+			{time.Hour, false, 0, time.Hour},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			l := newRateLimiter(config.RateLimitConfig{})
+			l.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				allowed, remaining, reset := l.take("key", tt.limit)
+				if allowed != s.wantAllowed || remaining != s.wantRemaining || reset != s.wantReset {
+					t.Errorf("step %d: take = %v, %d, %v, want %v, %d, %v", i, allowed, remaining, reset, s.wantAllowed, s.wantRemaining, s.wantReset)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(config.RateLimitConfig{})
+	l.now = func() time.Time { return now }
+	l.lastSweep = now
+	limit := config.RateLimit{Rate: 1, Burst: 1}
+
+	// Cada chave tem o seu balde
+	if allowed, _, _ := l.take("ip:1", limit); !allowed {
+		t.Fatal("first request of ip:1 denied")
+	}
+	if allowed, _, _ := l.take("ip:2", limit); !allowed {
+		t.Error("first request of ip:2 denied by the bucket of ip:1")
+	}
+	if allowed, _, _ := l.take("ip:1", limit); allowed {
+		t.Error("second request of ip:1 allowed")
+	}
+
+	// Baldes ociosos são removidos na próxima varredura, e os ativos são mantidos
+	now = now.Add(11 * time.Minute)
+	l.take("ip:3", limit)
+	if _, ok := l.buckets["ip:1"]; ok {
+		t.Error("idle bucket ip:1 was not swept")
+	}
+	if _, ok := l.buckets["ip:3"]; !ok {
+		t.Error("active bucket ip:3 was swept")
+	}
+}
+
+func TestUnversionedRoute(t *testing.T) {
+	tests := []struct {
+		route, want string
+	}{
+		{"/api/v1/rooms/{room_id}/messages", "/api/rooms/{room_id}/messages"},
+		{"/api/v2/rooms", "/api/rooms"},
+		{"/api/v2", "/api"},
+		{"/api/rooms", "/api/rooms"},
+		{"/api/vx/rooms", "/api/vx/rooms"},
+		{"/healthz", "/healthz"},
+	}
+	for _, tt := range tests {
+		if got := unversionedRoute(tt.route); got != tt.want {
+			t.Errorf("unversionedRoute(%q) = %q, want %q", tt.route, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// WithReportThreshold define o número de denúncias que oculta uma mensagem automaticamente.
+// Um valor menor ou igual a zero desativa a ocultação automática.
+func WithReportThreshold(n int) Option {
+	return func(h *apiHandler) {
+		h.reportThreshold = n
+	}
+}
+
+// handleReportMessage registra a denúncia de uma mensagem por um participante.
+func (h apiHandler) handleReportMessage(w http.ResponseWriter, r *http.Request) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.checkBan(w, r, roomID, BanKindBan) { // Participantes banidos não podem denunciar
+		return
+	}
+
+	rawID := chi.URLParam(r, "message_id") // Obtém o ID da mensagem da URL
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		http.Error(w, "invalid message id", http.StatusBadRequest)
+		return
+	}
+
+	type _body struct {
+		Reason string `json:"reason"`
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if utf8.RuneCountInString(body.Reason) > 255 {
+		http.Error(w, "reason too long", http.StatusBadRequest)
+		return
+	}
+
+	message, err := h.q.GetMessage(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "message not found", http.StatusNotFound)
+			return
+		}
+
+		slog.Error("failed to get message", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	// Apenas mensagens visíveis para a audiência podem ser denunciadas
+	if message.RoomID != roomID || message.ModerationStatus != ModerationStatusApproved {
+		http.Error(w, "message not found", http.StatusNotFound)
+		return
+	}
+
+	// Cada IP é contado uma única vez. O token de participante é assinado pelo servidor, mas qualquer cliente obtém
+	// quantos quiser em POST /participants; contar por participante deixaria um único cliente atingir o limite sozinho
+	// emitindo novas identidades, enquanto trocar de IP é bem mais caro
+	reporter := "ip:" + clientIP(r)
+
+	if _, err := h.q.InsertMessageReport(r.Context(), pgstore.InsertMessageReportParams{
+		MessageID: id,
+		Reporter:  reporter,
+		Reason:    body.Reason,
+	}); err != nil {
+		slog.Error("failed to insert message report", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	count, err := h.q.CountMessageReports(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to count message reports", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	// Notifica os clientes para que removam a mensagem e os moderadores para que a revisem
+	event := Message{
+		Kind:   MessageKindMessageHidden,
+		RoomID: rawRoomID,
+		Value: MessageMessageHidden{
+			ID: rawID,
+		},
+	}
+	hidden := false
+	if h.reportThreshold > 0 && count >= int64(h.reportThreshold) {
+		// Ao atingir o limite, a mensagem volta para a fila de moderação. Denúncias já revisadas por um moderador
+		// não são contadas, para que uma mensagem aprovada de novo não seja ocultada pela próxima denúncia
+		err := h.inRoomTx(r.Context(), roomID, func(q pgstore.Querier) error {
+			rows, err := q.HideMessage(r.Context(), id)
+			if err != nil || rows == 0 {
+				return err
+			}
+			hidden = true
+			return enqueueWebhooks(r.Context(), q, event)
+		})
+		if err != nil {
+			slog.Error("failed to hide reported message", "error", err)
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK) // Envia status 200 OK
+
+	if hidden {
+		go h.notifyClients(r.Context(), event)
+	}
+}
+
+// handleGetRoomReports lista as mensagens denunciadas de uma sala com o número de denúncias.
+func (h apiHandler) handleGetRoomReports(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	reports, err := h.q.GetRoomReportedMessages(r.Context(), roomID) // Obtém as mensagens denunciadas
+	if err != nil {
+		slog.Error("failed to get reported messages", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if reports == nil {
+		reports = []pgstore.GetRoomReportedMessagesRow{}
+	}
+
+	sendJSON(w, reports) // Envia a lista de mensagens denunciadas como resposta
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Handler é o handler HTTP da API, capaz de encerrar as suas conexões WebSocket de forma ordenada.
+type Handler interface {
+	http.Handler
+
+	// Shutdown marca o servidor como em encerramento, avisa todos os assinantes com o evento server_shutting_down,
+	// fecha as conexões WebSocket com o código 1001 (going away) e aguarda até que todas sejam liberadas ou ctx expire.
+	// Os clientes de long polling em espera são respondidos imediatamente.
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown implementa Handler.
+func (h apiHandler) Shutdown(ctx context.Context) error {
+	h.draining.Store(true) // /readyz passa a falhar e novas inscrições são recusadas
+
+	msg := Message{
+		Kind:  MessageKindServerShuttingDown,
+		Value: MessageServerShuttingDown{Reason: "server is shutting down"},
+	}
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down")
+
+	h.mu.Lock()
+	for roomID, subscribers := range h.subscribers {
+		slog.Info("closing room subscribers", "room_id", roomID, "subscribers", len(subscribers))
+		for conn, sub := range subscribers {
+			deadline := time.Now().Add(time.Second)
+			if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+				deadline = d
+			}
+
+			_ = conn.SetWriteDeadline(deadline)
+			event, _ := eventForVersion(msg, sub.version) // server_shutting_down existe em todas as versões
+			if err := conn.WriteJSON(event); err != nil {
+				slog.Warn("failed to send shutdown event to client", "error", err)
+			}
+			_ = conn.WriteControl(websocket.CloseMessage, closeMessage, deadline)
+			sub.cancel() // Encerra a inscrição; handleSubscribe fecha a conexão
+		}
+	}
+	h.mu.Unlock()
+
+	h.events.wakeAll() // Os clientes de long polling respondem com os eventos que já receberam
+
+	// Aguarda até que todos os handlers de inscrição tenham liberado as suas conexões
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		h.mu.Lock()
+		remaining := len(h.subscribers)
+		h.mu.Unlock()
+
+		if remaining == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
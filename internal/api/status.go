@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Estados de resposta de uma mensagem
+const (
+	MessageStatusOpen      = "open"      // Aguardando resposta
+	MessageStatusAnswering = "answering" // Sendo respondida
+	MessageStatusAnswered  = "answered"  // Respondida, ao vivo ou fora da sessão
+	MessageStatusDismissed = "dismissed" // Deixada de lado pelos moderadores
+	MessageStatusDuplicate = "duplicate" // Repete outra pergunta da sala
+)
+
+// messageStatusTransitions lista, para cada estado, os estados para os quais a mensagem pode passar.
+// Toda alteração pode ser desfeita voltando a mensagem para "open".
+var messageStatusTransitions = map[string][]string{
+	MessageStatusOpen:      {MessageStatusAnswering, MessageStatusAnswered, MessageStatusDismissed, MessageStatusDuplicate},
+	MessageStatusAnswering: {MessageStatusOpen, MessageStatusAnswered, MessageStatusDismissed},
+	MessageStatusAnswered:  {MessageStatusOpen},
+	MessageStatusDismissed: {MessageStatusOpen, MessageStatusAnswered},
+	MessageStatusDuplicate: {MessageStatusOpen},
+}
+
+var (
+	errInvalidStatusTransition = errors.New("invalid status transition")
+	errStatusChanged           = errors.New("message status has changed")
+	errNotApproved             = errors.New("message is not approved") // Somente mensagens aprovadas ficam em destaque
+)
+
+// handleUpdateMessageStatus altera o estado de resposta de uma mensagem (moderador).
+func (h apiHandler) handleUpdateMessageStatus(w http.ResponseWriter, r *http.Request) {
+	_, rawRoomID, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		Status string `json:"status"`
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := messageStatusTransitions[body.Status]; !ok {
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+
+	message, ok := h.changeMessageStatus(w, r, rawRoomID, roomID, body.Status)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(r.Context(), message.Version, ""))
+	sendJSON(w, messageForVersion(apiVersion(r.Context()), message)) // Envia a mensagem atualizada como resposta
+}
+
+// changeMessageStatus valida a transição e altera o estado de resposta da mensagem da URL, notificando os clientes.
+// Alterar para o estado atual não tem efeito. Se a alteração falhar, responde com o erro e retorna false.
+func (h apiHandler) changeMessageStatus(w http.ResponseWriter, r *http.Request, rawRoomID string, roomID uuid.UUID, status string) (pgstore.Message, bool) {
+	rawID := chi.URLParam(r, "message_id") // Obtém o ID da mensagem da URL
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		http.Error(w, "invalid message id", http.StatusBadRequest)
+		return pgstore.Message{}, false
+	}
+
+	var (
+		message   pgstore.Message
+		oldStatus string
+		events    []Message
+	)
+	err = h.ifMatch(r, roomID, lockMessage(id, roomID), func(q pgstore.Querier) (err error) {
+		message, oldStatus, events, err = applyMessageStatus(r.Context(), q, rawRoomID, roomID, id, status)
+		if err != nil {
+			return err
+		}
+		return enqueueWebhooks(r.Context(), q, events...)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errPreconditionFailed):
+			http.Error(w, "message has changed", http.StatusPreconditionFailed)
+		case errors.Is(err, pgx.ErrNoRows):
+			http.Error(w, "message not found", http.StatusNotFound)
+		case errors.Is(err, errInvalidStatusTransition):
+			http.Error(w, fmt.Sprintf("cannot change status from %s to %s", oldStatus, status), http.StatusConflict)
+		case errors.Is(err, errStatusChanged):
+			http.Error(w, "message status has changed", http.StatusConflict)
+		case errors.Is(err, errNotApproved):
+			http.Error(w, "only approved messages can be answering", http.StatusConflict)
+		default:
+			slog.Error("failed to update message status", "error", err)
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
+		}
+		return pgstore.Message{}, false
+	}
+
+	go func() {
+		for _, msg := range events {
+			h.notifyClients(r.Context(), msg) // Enviados em ordem
+		}
+	}()
+
+	return message, true
+}
+
+// applyMessageStatus valida a transição e altera, com as consultas q, o estado de resposta da mensagem id da sala.
+// O destaque da sala acompanha o estado "answering" (veja linkNowAnswering). Retorna a mensagem, o estado anterior
+// e os eventos a enviar, em ordem; alterar para o estado atual não tem efeito e não produz eventos.
+// Como pode alterar mais de uma mensagem, q deve ser uma transação aberta com inRoomTx (ou ifMatch).
+func applyMessageStatus(ctx context.Context, q pgstore.Querier, rawRoomID string, roomID, id uuid.UUID, status string) (pgstore.Message, string, []Message, error) {
+	message, err := q.GetMessage(ctx, id)
+	if err != nil {
+		return pgstore.Message{}, "", nil, err
+	}
+	if message.RoomID != roomID {
+		return pgstore.Message{}, "", nil, pgx.ErrNoRows
+	}
+
+	oldStatus := message.Status
+	if oldStatus == status {
+		return message, oldStatus, nil, nil
+	}
+	if !slices.Contains(messageStatusTransitions[oldStatus], status) {
+		return pgstore.Message{}, oldStatus, nil, errInvalidStatusTransition
+	}
+
+	// A alteração só é feita se o estado não mudou desde a leitura, para que a transição validada seja a aplicada
+	message, err = q.UpdateMessageStatus(ctx, pgstore.UpdateMessageStatusParams{
+		Status:    status,
+		ID:        id,
+		RoomID:    roomID,
+		OldStatus: oldStatus,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pgstore.Message{}, oldStatus, nil, errStatusChanged
+	}
+	if err != nil {
+		return pgstore.Message{}, oldStatus, nil, err
+	}
+
+	linked, err := linkNowAnswering(ctx, q, rawRoomID, message, oldStatus)
+	if err != nil {
+		return pgstore.Message{}, oldStatus, nil, err
+	}
+	return message, oldStatus, append(messageStatusEvents(rawRoomID, message, oldStatus), linked...), nil
+}
+
+// linkNowAnswering mantém a mensagem em destaque da sala (rooms.now_answering_id) de acordo com o estado "answering":
+// a mensagem que passa a ser respondida entra em destaque e a que estava sendo respondida volta para "open";
+// a que deixa de ser respondida sai do destaque. Retorna os eventos dessas alterações.
+func linkNowAnswering(ctx context.Context, q pgstore.Querier, rawRoomID string, message pgstore.Message, oldStatus string) ([]Message, error) {
+	switch {
+	case message.Status == MessageStatusAnswering:
+		events, err := demoteAnswering(ctx, q, rawRoomID, message.RoomID, message.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = q.SetRoomNowAnswering(ctx, pgstore.SetRoomNowAnsweringParams{RoomID: message.RoomID, MessageID: message.ID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errNotApproved
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Notifica os clientes assinantes da sala sobre a mensagem sendo respondida
+		return append(events, Message{
+			Kind:   MessageKindNowAnswering,
+			RoomID: rawRoomID,
+			Value: MessageNowAnswering{
+				ID:      message.ID.String(),
+				Message: message.Message,
+			},
+		}), nil
+
+	case oldStatus == MessageStatusAnswering:
+		room, err := q.GetRoom(ctx, message.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		if !room.NowAnsweringID.Valid || room.NowAnsweringID.Bytes != message.ID {
+			return nil, nil
+		}
+
+		if _, err := q.ClearRoomNowAnswering(ctx, room.ID); err != nil {
+			return nil, err
+		}
+
+		// Notifica os clientes assinantes da sala de que nenhuma mensagem está sendo respondida
+		return []Message{{
+			Kind:   MessageKindNowAnswering,
+			RoomID: rawRoomID,
+			Value:  MessageNowAnswering{},
+		}}, nil
+	}
+	return nil, nil
+}
+
+// demoteAnswering volta para "open" as mensagens da sala sendo respondidas, exceto except, e retorna os eventos.
+func demoteAnswering(ctx context.Context, q pgstore.Querier, rawRoomID string, roomID, except uuid.UUID) ([]Message, error) {
+	messages, err := q.GetRoomMessages(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Message
+	for _, m := range messages {
+		if m.ID == except || m.Status != MessageStatusAnswering {
+			continue
+		}
+
+		demoted, err := q.UpdateMessageStatus(ctx, pgstore.UpdateMessageStatusParams{
+			Status:    MessageStatusOpen,
+			ID:        m.ID,
+			RoomID:    roomID,
+			OldStatus: MessageStatusAnswering,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, messageStatusEvents(rawRoomID, demoted, MessageStatusAnswering)...)
+	}
+	return events, nil
+}
+
+// messageStatusEvents retorna os eventos da mudança de estado da mensagem, na ordem em que devem ser enviados.
+func messageStatusEvents(rawRoomID string, message pgstore.Message, oldStatus string) []Message {
+	// Mensagens fora da fila pública só têm o estado enviado aos moderadores
+	moderatorsOnly := message.ModerationStatus != ModerationStatusApproved
+
+	// Notifica os clientes assinantes da sala sobre a mudança de estado
+	events := []Message{{
+		Kind:           MessageKindMessageStatusChanged,
+		RoomID:         rawRoomID,
+		ModeratorsOnly: moderatorsOnly,
+		Value: MessageMessageStatusChanged{
+			ID:        message.ID.String(),
+			OldStatus: oldStatus,
+			NewStatus: message.Status,
+		},
+	}}
+	if message.Status == MessageStatusAnswered {
+		// Enviado apenas aos clientes da v1, que não recebem message_status_changed
+		events = append(events, Message{
+			Kind:           MessageKindMessageAnswered,
+			RoomID:         rawRoomID,
+			ModeratorsOnly: moderatorsOnly,
+			Value: MessageMessageAnswered{
+				ID: message.ID.String(),
+			},
+		})
+	}
+	return events
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/config"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+	"github.com/joao-ressel/go-server/internal/store/pgstore/pgstoretest"
+)
+
+// serve envia uma requisição à v2 da API e retorna a resposta, decodificando o corpo JSON em out, se não for nil.
+func serve(t *testing.T, h http.Handler, method, path string, body any, header http.Header, out any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, "/api/v2"+path, &reader)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if out != nil && rec.Code < http.StatusBadRequest {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode %s: %v", method, path, rec.Body, err)
+		}
+	}
+	return rec
+}
+
+// TestMessageStatusConcurrentWithReactions alterna a mensagem sendo respondida enquanto as duas mensagens recebem reações.
+// Cada troca altera as duas mensagens e a sala, e cada reação altera uma mensagem e, pelo gatilho de versão, a sala;
+// sem o bloqueio da sala no início das transações, o PostgreSQL aborta uma delas por deadlock.
+func TestMessageStatusConcurrentWithReactions(t *testing.T) {
+	pool := pgstoretest.New(t)
+	h := NewHandler(pgstore.New(pool),
+		WithDatabase(pool),
+		WithRateLimit(config.RateLimitConfig{Default: config.RateLimit{Rate: 1000, Burst: 1000}}),
+	)
+
+	var created struct {
+		ID             string `json:"id"`
+		ModeratorToken string `json:"moderator_token"`
+	}
+	serve(t, h, http.MethodPost, "/rooms", map[string]any{"theme": "deadlocks"}, nil, &created)
+	room := "/rooms/" + created.ID
+	mod := http.Header{"Authorization": {"Bearer " + created.ModeratorToken}}
+
+	messages := make([]string, 2)
+	for i := range messages {
+		var message struct {
+			ID string `json:"id"`
+		}
+		serve(t, h, http.MethodPost, room+"/messages", map[string]any{"message": "question"}, nil, &message)
+		messages[i] = room + "/messages/" + message.ID
+	}
+
+	const rounds = 50
+	var wg sync.WaitGroup
+	for _, message := range messages {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range rounds {
+				for _, status := range []string{MessageStatusAnswering, MessageStatusOpen} {
+					if rec := serve(t, h, http.MethodPatch, message+"/status", map[string]any{"status": status}, mod, nil); rec.Code != http.StatusOK {
+						t.Errorf("status %s: %d %s", status, rec.Code, rec.Body)
+						return
+					}
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range rounds {
+				for _, method := range []string{http.MethodPatch, http.MethodDelete} {
+					if rec := serve(t, h, method, message+"/react", nil, nil, nil); rec.Code != http.StatusOK {
+						t.Errorf("%s react: %d %s", method, rec.Code, rec.Body)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// No fim, a sala e os estados das mensagens continuam de acordo
+	var details struct {
+		NowAnsweringID *string `json:"now_answering_id"`
+	}
+	serve(t, h, http.MethodGet, room, nil, nil, &details)
+	answering := 0
+	for _, message := range messages {
+		var m struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		}
+		serve(t, h, http.MethodGet, message, nil, nil, &m)
+		if m.Status == MessageStatusAnswering {
+			answering++
+			if details.NowAnsweringID == nil || *details.NowAnsweringID != m.ID {
+				t.Errorf("message %s is answering but now_answering_id = %v", m.ID, details.NowAnsweringID)
+			}
+		}
+	}
+	if answering == 0 && details.NowAnsweringID != nil {
+		t.Errorf("no message is answering but now_answering_id = %s", *details.NowAnsweringID)
+	}
+}
+
+func TestMessageStatusTransitions(t *testing.T) {
+	open, answering, answered, dismissed, duplicate := MessageStatusOpen, MessageStatusAnswering, MessageStatusAnswered, MessageStatusDismissed, MessageStatusDuplicate
+	statuses := []string{open, answering, answered, dismissed, duplicate}
+
+	// allowed[de][para]; as transições para o próprio estado não têm efeito e não estão na tabela
+	allowed := map[string]map[string]bool{
+		open:      {answering: true, answered: true, dismissed: true, duplicate: true},
+		answering: {open: true, answered: true, dismissed: true},
+		answered:  {open: true},
+		dismissed: {open: true, answered: true},
+		duplicate: {open: true},
+	}
+	if len(messageStatusTransitions) != len(statuses) {
+		t.Errorf("messageStatusTransitions has %d states, want %d", len(messageStatusTransitions), len(statuses))
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(from+"->"+to, func(t *testing.T) {
+				if got := slices.Contains(messageStatusTransitions[from], to); got != allowed[from][to] {
+					t.Errorf("transition allowed = %v, want %v", got, allowed[from][to])
+				}
+			})
+		}
+	}
+}
+
+func TestMessageStatusEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		moderation string
+		old, new   string
+		wantKinds  []string
+		wantModsOn bool
+	}{
+		{"answered", ModerationStatusApproved, MessageStatusOpen, MessageStatusAnswered, []string{MessageKindMessageStatusChanged, MessageKindMessageAnswered}, false},
+		{"reopened", ModerationStatusApproved, MessageStatusAnswered, MessageStatusOpen, []string{MessageKindMessageStatusChanged}, false},
+		{"answering", ModerationStatusApproved, MessageStatusOpen, MessageStatusAnswering, []string{MessageKindMessageStatusChanged}, false},
+		{"pending message", ModerationStatusPending, MessageStatusOpen, MessageStatusAnswered, []string{MessageKindMessageStatusChanged, MessageKindMessageAnswered}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := pgstore.Message{ID: uuid.New(), ModerationStatus: tt.moderation, Status: tt.new}
+			events := messageStatusEvents("room", message, tt.old)
+
+			var kinds []string
+			for _, e := range events {
+				kinds = append(kinds, e.Kind)
+				if e.ModeratorsOnly != tt.wantModsOn {
+					t.Errorf("%s: moderators only = %v, want %v", e.Kind, e.ModeratorsOnly, tt.wantModsOn)
+				}
+			}
+			if !slices.Equal(kinds, tt.wantKinds) {
+				t.Errorf("event kinds = %v, want %v", kinds, tt.wantKinds)
+			}
+			if changed := events[0].Value.(MessageMessageStatusChanged); changed.OldStatus != tt.old || changed.NewStatus != tt.new {
+				t.Errorf("status changed from %s to %s, want %s to %s", changed.OldStatus, changed.NewStatus, tt.old, tt.new)
+			}
+		})
+	}
+}
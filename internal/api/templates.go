@@ -0,0 +1,267 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/joao-ressel/go-server/internal/filter"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// maxImportSize é o tamanho máximo do corpo de uma importação.
+const maxImportSize = 10 << 20
+
+// maxMessageLength é o tamanho máximo de uma mensagem, conforme a coluna messages.message.
+const maxMessageLength = 255
+
+var (
+	// errNoDatabase indica que o handler foi criado sem WithDatabase e não pode abrir transações.
+	errNoDatabase = errors.New("no database configured for transactions")
+	// errNoTxQuerier indica que o pgstore.Querier do handler não implementa TxQuerier.
+	errNoTxQuerier = errors.New("querier does not support transactions")
+)
+
+// inTx executa fn em uma transação, confirmando-a apenas se fn não retornar erro.
+func (h apiHandler) inTx(ctx context.Context, fn func(q pgstore.Querier) error) error {
+	if h.db == nil {
+		return errNoDatabase
+	}
+
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }() // Sem efeito depois do Commit
+
+	q, ok := withTx(h.q, tx)
+	if !ok {
+		return errNoTxQuerier
+	}
+	if err := fn(q); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// inOptionalTx executa fn em uma transação ou, se o handler foi criado sem WithDatabase, diretamente.
+func (h apiHandler) inOptionalTx(ctx context.Context, fn func(q pgstore.Querier) error) error {
+	if h.db == nil {
+		return fn(h.q)
+	}
+	return h.inTx(ctx, fn)
+}
+
+// TxQuerier é implementada pelos pgstore.Querier que não são *pgstore.Queries, como os usados nos testes,
+// para executar as consultas na transação aberta por DB.Begin.
+type TxQuerier interface {
+	pgstore.Querier
+	WithTx(tx pgx.Tx) pgstore.Querier
+}
+
+// withTx retorna q vinculado à transação tx, ou false se q não puder participar de transações.
+func withTx(q pgstore.Querier, tx pgx.Tx) (pgstore.Querier, bool) {
+	switch q := q.(type) {
+	case *pgstore.Queries:
+		return q.WithTx(tx), true
+	case TxQuerier:
+		return q.WithTx(tx), true
+	}
+	return nil, false
+}
+
+// createRoom insere uma sala e o seu token de moderador, retornando o ID e o token em texto.
+func createRoom(ctx context.Context, q pgstore.Querier, params pgstore.InsertRoomParams) (uuid.UUID, string, error) {
+	token, tokenHash, err := newModeratorToken()
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	roomID, err := q.InsertRoom(ctx, params)
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	err = q.InsertRoomModeratorToken(ctx, pgstore.InsertRoomModeratorTokenParams{RoomID: roomID, TokenHash: tokenHash})
+	if err != nil {
+		return uuid.UUID{}, "", err
+	}
+
+	return roomID, token, nil
+}
+
+// handleImportRoom cria uma sala a partir de uma exportação em JSON (GET /export?format=json).
+// A sala, o token de moderador e as mensagens são gravados em uma única transação; IDs da exportação são ignorados.
+func (h apiHandler) handleImportRoom(w http.ResponseWriter, r *http.Request) {
+	type _message struct {
+		Message          string `json:"message"`
+		ReactionCount    int64  `json:"reaction_count"`
+		Answered         bool   `json:"answered"` // Usado quando status não é informado, como nas exportações anteriores a ele
+		Status           string `json:"status"`
+		ModerationStatus string `json:"moderation_status"`
+		Attribution      string `json:"attribution"`
+	}
+	type _body struct {
+		Room struct {
+			Theme     string `json:"theme"`
+			Moderated bool   `json:"moderated"`
+		} `json:"room"`
+		Messages []_message `json:"messages"`
+	}
+	var body _body
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportSize)).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	statuses := []string{ModerationStatusPending, ModerationStatusApproved, ModerationStatusRejected}
+	rules := h.filter.Defaults() // As mensagens importadas passam pelo filtro padrão, já que a sala nova ainda não tem regras próprias
+	for i := range body.Messages {
+		m := &body.Messages[i]
+		m.ModerationStatus = cmp.Or(m.ModerationStatus, ModerationStatusApproved)
+		if m.Status == "" {
+			m.Status = MessageStatusOpen
+			if m.Answered {
+				m.Status = MessageStatusAnswered
+			}
+		}
+
+		switch {
+		case m.Message == "" || utf8.RuneCountInString(m.Message) > maxMessageLength:
+			http.Error(w, fmt.Sprintf("message %d: message must have between 1 and %d characters", i, maxMessageLength), http.StatusBadRequest)
+			return
+		case m.ReactionCount < 0:
+			http.Error(w, fmt.Sprintf("message %d: invalid reaction count", i), http.StatusBadRequest)
+			return
+		case !slices.Contains(statuses, m.ModerationStatus):
+			http.Error(w, fmt.Sprintf("message %d: invalid moderation status", i), http.StatusBadRequest)
+			return
+		case messageStatusTransitions[m.Status] == nil:
+			http.Error(w, fmt.Sprintf("message %d: invalid status", i), http.StatusBadRequest)
+			return
+		case utf8.RuneCountInString(m.Attribution) > maxAttributionLength:
+			http.Error(w, fmt.Sprintf("message %d: attribution is too long", i), http.StatusBadRequest)
+			return
+		}
+
+		result := rules.Check(m.Message)
+		switch result.Action {
+		case filter.ActionReject:
+			http.Error(w, fmt.Sprintf("message %d rejected by content filter", i), http.StatusUnprocessableEntity)
+			return
+		case filter.ActionModerate:
+			if m.ModerationStatus == ModerationStatusApproved {
+				m.ModerationStatus = ModerationStatusPending
+			}
+		}
+		m.Message = result.Text
+	}
+
+	var (
+		roomID uuid.UUID
+		token  string
+	)
+	err := h.inOptionalTx(r.Context(), func(q pgstore.Querier) error {
+		var err error
+		roomID, token, err = createRoom(r.Context(), q, pgstore.InsertRoomParams{Theme: body.Room.Theme, Moderated: body.Room.Moderated})
+		if err != nil {
+			return err
+		}
+
+		for _, m := range body.Messages {
+			err := q.ImportMessage(r.Context(), pgstore.ImportMessageParams{
+				RoomID:           roomID,
+				Message:          m.Message,
+				ReactionCount:    m.ReactionCount,
+				Status:           m.Status,
+				ModerationStatus: m.ModerationStatus,
+				Attribution:      m.Attribution,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to import room", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	type response struct {
+		ID               string `json:"id"`
+		ModeratorToken   string `json:"moderator_token"`
+		MessagesImported int    `json:"messages_imported"`
+	}
+
+	sendJSON(w, response{ID: roomID.String(), ModeratorToken: token, MessagesImported: len(body.Messages)}) // Envia o ID da nova sala e o token de moderador como resposta
+}
+
+// handleCloneRoom cria uma sala com as mesmas configurações e regras de filtro de outra (moderador).
+// Opcionalmente, as perguntas ainda não respondidas são copiadas para a sala nova. Tudo é feito em uma única transação.
+func (h apiHandler) handleCloneRoom(w http.ResponseWriter, r *http.Request) {
+	room, _, roomID, ok := h.readRoom(w, r) // Obtém a sala de origem
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		Theme               string `json:"theme"` // Vazio mantém o tema da sala de origem
+		CarryOverUnanswered bool   `json:"carry_over_unanswered"`
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) { // O corpo é opcional
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		cloneID uuid.UUID
+		token   string
+		copied  int64
+	)
+	err := h.inOptionalTx(r.Context(), func(q pgstore.Querier) error {
+		var err error
+		cloneID, token, err = createRoom(r.Context(), q, pgstore.InsertRoomParams{Theme: cmp.Or(body.Theme, room.Theme), Moderated: room.Moderated})
+		if err != nil {
+			return err
+		}
+
+		err = q.CopyRoomFilterRules(r.Context(), pgstore.CopyRoomFilterRulesParams{TargetRoomID: cloneID, SourceRoomID: roomID})
+		if err != nil {
+			return err
+		}
+
+		if body.CarryOverUnanswered {
+			copied, err = q.CopyUnansweredMessages(r.Context(), pgstore.CopyUnansweredMessagesParams{TargetRoomID: cloneID, SourceRoomID: roomID})
+		}
+		return err
+	})
+	if err != nil {
+		slog.Error("failed to clone room", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	type response struct {
+		ID             string `json:"id"`
+		ModeratorToken string `json:"moderator_token"`
+		MessagesCopied int64  `json:"messages_copied"`
+	}
+
+	sendJSON(w, response{ID: cloneID.String(), ModeratorToken: token, MessagesCopied: copied}) // Envia o ID da nova sala e o token de moderador como resposta
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/joao-ressel/go-server/internal/config"
+	"github.com/joao-ressel/go-server/internal/store/pgstore/pgstoretest"
+)
+
+// TestCreateRoomWithoutDatabase cria, importa e clona salas em um handler criado sem WithDatabase,
+// em que as consultas são executadas diretamente, sem transação.
+func TestCreateRoomWithoutDatabase(t *testing.T) {
+	h := NewHandler(pgstoretest.NewMemory(),
+		WithRateLimit(config.RateLimitConfig{Default: config.RateLimit{Rate: 1000, Burst: 1000}}),
+	)
+
+	var created struct {
+		ID             string `json:"id"`
+		ModeratorToken string `json:"moderator_token"`
+	}
+	if rec := serve(t, h, http.MethodPost, "/rooms", map[string]any{"theme": "no database"}, nil, &created); rec.Code != http.StatusOK {
+		t.Fatalf("create room: %d %s", rec.Code, rec.Body)
+	}
+
+	body := map[string]any{
+		"room":     map[string]any{"theme": "imported"},
+		"messages": []map[string]any{{"message": "question", "status": MessageStatusOpen}},
+	}
+	if rec := serve(t, h, http.MethodPost, "/rooms/import", body, nil, nil); rec.Code != http.StatusOK {
+		t.Errorf("import room: %d %s", rec.Code, rec.Body)
+	}
+
+	mod := http.Header{"Authorization": {"Bearer " + created.ModeratorToken}}
+	if rec := serve(t, h, http.MethodPost, "/rooms/"+created.ID+"/clone", map[string]any{"theme": "cloned"}, mod, nil); rec.Code != http.StatusOK {
+		t.Errorf("clone room: %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Versões da API HTTP e do envelope das mensagens de WebSocket.
+// O formato de uma versão publicada não muda; alterações incompatíveis entram em uma versão nova.
+// A v2 acrescenta às salas e mensagens os campos de estado, destaque, versão e datas, e os eventos
+// message_status_changed, message_pinned e now_answering.
+const (
+	APIVersion1      = 1
+	APIVersion2      = 2
+	LatestAPIVersion = APIVersion2
+)
+
+// supportedAPIVersions lista as versões atendidas pelo servidor.
+var supportedAPIVersions = []int{APIVersion1, APIVersion2}
+
+// webSocketSubprotocolPrefix é o prefixo do subprotocolo usado para negociar a versão no upgrade ("wsrs.v1").
+const webSocketSubprotocolPrefix = "wsrs.v"
+
+type apiVersionKey struct{}
+
+// withAPIVersion é o middleware que associa a versão da API às requisições de um grupo de rotas.
+// Handlers compartilhados entre versões usam apiVersion para escolher o formato da resposta.
+func withAPIVersion(version int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), apiVersionKey{}, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// apiVersion retorna a versão da API da requisição; rotas fora de um grupo versionado usam a v1.
+func apiVersion(ctx context.Context) int {
+	if version, ok := ctx.Value(apiVersionKey{}).(int); ok {
+		return version
+	}
+	return APIVersion1
+}
+
+// unversionedRoute remove a versão de um padrão de rota ("/api/v1/rooms" vira "/api/rooms"),
+// para que limites e documentação sejam compartilhados entre /api e /api/vN.
+func unversionedRoute(route string) string {
+	rest, ok := strings.CutPrefix(route, "/api/v")
+	if !ok {
+		return route
+	}
+
+	version, rest, _ := strings.Cut(rest, "/")
+	if _, err := strconv.Atoi(version); err != nil {
+		return route
+	}
+	if rest == "" {
+		return "/api"
+	}
+	return "/api/" + rest
+}
+
+// negotiateWebSocketVersion escolhe a versão do envelope das mensagens de uma nova inscrição.
+// O cliente pode informar a versão no parâmetro version ou oferecer subprotocolos "wsrs.vN"; neste caso é escolhida
+// a maior versão suportada, devolvida em subprotocol. Sem nenhuma das duas, é usada a v1.
+func negotiateWebSocketVersion(r *http.Request) (version int, subprotocol string, ok bool) {
+	if raw := r.URL.Query().Get("version"); raw != "" {
+		version, err := strconv.Atoi(raw)
+		if err != nil || !slices.Contains(supportedAPIVersions, version) {
+			return 0, "", false
+		}
+		return version, "", true
+	}
+
+	offered := false
+	for _, protocol := range websocketSubprotocols(r) {
+		raw, found := strings.CutPrefix(protocol, webSocketSubprotocolPrefix)
+		if !found {
+			continue
+		}
+		offered = true
+
+		if v, err := strconv.Atoi(raw); err == nil && slices.Contains(supportedAPIVersions, v) && v > version {
+			version, subprotocol = v, protocol
+		}
+	}
+	if offered && version == 0 {
+		return 0, "", false
+	}
+	if version == 0 {
+		version = APIVersion1
+	}
+	return version, subprotocol, true
+}
+
+// websocketSubprotocols retorna os subprotocolos oferecidos no cabeçalho Sec-WebSocket-Protocol.
+func websocketSubprotocols(r *http.Request) []string {
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// roomV1 é o formato de uma sala na v1.
+type roomV1 struct {
+	ID        uuid.UUID `json:"id"`
+	Theme     string    `json:"theme"`
+	Moderated bool      `json:"moderated"`
+}
+
+// messageV1 é o formato de uma mensagem na v1; answered indica se o estado da mensagem é "answered".
+type messageV1 struct {
+	ID               uuid.UUID `json:"id"`
+	RoomID           uuid.UUID `json:"room_id"`
+	Message          string    `json:"message"`
+	ReactionCount    int64     `json:"reaction_count"`
+	Answered         bool      `json:"answered"`
+	ModerationStatus string    `json:"moderation_status"`
+}
+
+// messageCreatedV1 é o valor dos eventos message_created e message_pending na v1, sem a atribuição.
+type messageCreatedV1 struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// eventKindsV1 lista os eventos da v1; os criados depois só são enviados aos clientes das versões seguintes.
+var eventKindsV1 = []string{
+	MessageKindMessageCreated,
+	MessageKindMessageRactionIncreased,
+	MessageKindMessageRactionDecreased,
+	MessageKindMessageAnswered,
+	MessageKindMessagePending,
+	MessageKindMessageRejected,
+	MessageKindMessageHidden,
+	MessageKindServerShuttingDown,
+}
+
+// eventKindsV1Only lista os eventos substituídos nas versões seguintes, enviados apenas aos clientes da v1.
+var eventKindsV1Only = []string{
+	MessageKindMessageAnswered, // Substituído por message_status_changed
+}
+
+func newRoomV1(room pgstore.Room) roomV1 {
+	return roomV1{ID: room.ID, Theme: room.Theme, Moderated: room.Moderated}
+}
+
+func newMessageV1(m pgstore.Message) messageV1 {
+	return messageV1{
+		ID:               m.ID,
+		RoomID:           m.RoomID,
+		Message:          m.Message,
+		ReactionCount:    m.ReactionCount,
+		Answered:         m.Answered,
+		ModerationStatus: m.ModerationStatus,
+	}
+}
+
+// roomForVersion retorna a sala no formato da versão informada.
+func roomForVersion(version int, room pgstore.Room) any {
+	if version == APIVersion1 {
+		return newRoomV1(room)
+	}
+	return room
+}
+
+// roomsForVersion retorna a lista de salas no formato da versão informada.
+func roomsForVersion(version int, rooms []pgstore.Room) any {
+	if version != APIVersion1 {
+		return rooms
+	}
+	out := make([]roomV1, len(rooms))
+	for i, room := range rooms {
+		out[i] = newRoomV1(room)
+	}
+	return out
+}
+
+// messageForVersion retorna a mensagem no formato da versão informada.
+func messageForVersion(version int, m pgstore.Message) any {
+	if version == APIVersion1 {
+		return newMessageV1(m)
+	}
+	return m
+}
+
+// messagesForVersion retorna a lista de mensagens no formato da versão informada.
+func messagesForVersion(version int, messages []pgstore.Message) any {
+	if version != APIVersion1 {
+		return messages
+	}
+	out := make([]messageV1, len(messages))
+	for i, m := range messages {
+		out[i] = newMessageV1(m)
+	}
+	return out
+}
+
+// eventForVersion prepara o evento para um cliente da versão informada, preenchendo a versão do envelope.
+// Retorna false se o evento não existe nessa versão e não deve ser enviado.
+func eventForVersion(msg Message, version int) (Message, bool) {
+	msg.Version = version
+	if version != APIVersion1 {
+		return msg, !slices.Contains(eventKindsV1Only, msg.Kind)
+	}
+
+	if !slices.Contains(eventKindsV1, msg.Kind) {
+		return msg, false
+	}
+	switch v := msg.Value.(type) {
+	case MessageMessageCreated:
+		msg.Value = messageCreatedV1{ID: v.ID, Message: v.Message}
+	case MessageMessagePending:
+		msg.Value = messageCreatedV1{ID: v.ID, Message: v.Message}
+	}
+	return msg, true
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// maxWebhookURLLength é o tamanho máximo da URL de um webhook, conforme a coluna room_webhooks.url.
+const maxWebhookURLLength = 2048
+
+// Limites do registro de entregas retornado por GET /webhooks/deliveries
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+// webhookEventKinds são os tipos de evento que podem ser assinados por um webhook. Os payloads seguem a versão mais
+// recente, e por isso message_answered, substituído por message_status_changed, não é enviado aos webhooks.
+var webhookEventKinds = []string{
+	MessageKindMessageCreated,
+	MessageKindMessageRactionIncreased,
+	MessageKindMessageRactionDecreased,
+	MessageKindMessageStatusChanged,
+	MessageKindMessagePending,
+	MessageKindMessageRejected,
+	MessageKindMessageHidden,
+	MessageKindMessagePinned,
+	MessageKindNowAnswering,
+}
+
+// WebhookPayload é o corpo enviado aos webhooks; Value tem o mesmo formato do evento enviado via WebSocket na versão mais recente.
+type WebhookPayload struct {
+	Version   int       `json:"version"`
+	Kind      string    `json:"kind"`
+	RoomID    string    `json:"room_id"`
+	Value     any       `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// enqueueWebhooks grava no outbox uma entrega de cada evento para cada webhook da sala interessado no seu tipo.
+// q deve ser a transação da alteração que produziu os eventos, para que as entregas sejam gravadas se, e somente se,
+// a alteração for confirmada. As entregas são enviadas depois pelo webhook.Dispatcher.
+func enqueueWebhooks(ctx context.Context, q pgstore.Querier, events ...Message) error {
+	for _, msg := range events {
+		if !slices.Contains(webhookEventKinds, msg.Kind) {
+			continue // Eventos que existem apenas na v1
+		}
+
+		roomID, err := uuid.Parse(msg.RoomID)
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(WebhookPayload{
+			Version:   LatestAPIVersion,
+			Kind:      msg.Kind,
+			RoomID:    msg.RoomID,
+			Value:     msg.Value,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.InsertWebhookDeliveries(ctx, pgstore.InsertWebhookDeliveriesParams{
+			EventKind: msg.Kind,
+			Payload:   string(payload),
+			RoomID:    roomID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleGetRoomWebhooks lista os webhooks de uma sala, sem os segredos.
+func (h apiHandler) handleGetRoomWebhooks(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	webhooks, err := h.q.GetRoomWebhooks(r.Context(), roomID)
+	if err != nil {
+		slog.Error("failed to get room webhooks", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if webhooks == nil {
+		webhooks = []pgstore.GetRoomWebhooksRow{}
+	}
+
+	sendJSON(w, webhooks) // Envia a lista de webhooks como resposta
+}
+
+// handleCreateRoomWebhook cadastra um webhook em uma sala.
+// O segredo usado nas assinaturas é gerado pelo servidor e só é devolvido nesta resposta.
+func (h apiHandler) handleCreateRoomWebhook(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	type _body struct {
+		URL        string   `json:"url"`
+		EventKinds []string `json:"event_kinds"` // Vazio assina todos os tipos de evento
+	}
+	var body _body
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	u, err := url.Parse(body.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(body.URL) > maxWebhookURLLength {
+		http.Error(w, "invalid url", http.StatusBadRequest)
+		return
+	}
+
+	kinds := []string{} // A coluna não aceita NULL
+	for _, kind := range body.EventKinds {
+		if !slices.Contains(webhookEventKinds, kind) {
+			http.Error(w, "invalid event kind: "+kind, http.StatusBadRequest)
+			return
+		}
+		if !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		slog.Error("failed to generate webhook secret", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	webhook, err := h.q.InsertRoomWebhook(r.Context(), pgstore.InsertRoomWebhookParams{
+		RoomID:     roomID,
+		Url:        body.URL,
+		Secret:     hex.EncodeToString(buf),
+		EventKinds: kinds,
+	})
+	if err != nil {
+		slog.Error("failed to insert room webhook", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	sendJSON(w, webhook) // Envia o webhook criado, com o segredo, como resposta
+}
+
+// handleDeleteRoomWebhook remove um webhook de uma sala, junto com o seu registro de entregas.
+func (h apiHandler) handleDeleteRoomWebhook(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhook_id")) // Obtém o ID do webhook da URL
+	if err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.q.DeleteRoomWebhook(r.Context(), pgstore.DeleteRoomWebhookParams{ID: webhookID, RoomID: roomID})
+	if err != nil {
+		slog.Error("failed to delete room webhook", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if deleted == 0 {
+		http.Error(w, "webhook not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK) // Envia status 200 OK
+}
+
+// handleGetRoomWebhookDeliveries retorna as entregas mais recentes dos webhooks de uma sala.
+// O parâmetro limit define quantas entregas são retornadas (padrão 50, máximo 200).
+func (h apiHandler) handleGetRoomWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	_, _, roomID, ok := h.readRoom(w, r) // Obtém o ID da sala
+	if !ok {
+		return
+	}
+
+	if !h.requireModerator(w, r, roomID) {
+		return
+	}
+
+	limit := defaultWebhookDeliveriesLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxWebhookDeliveriesLimit)
+	}
+
+	deliveries, err := h.q.GetRoomWebhookDeliveries(r.Context(), pgstore.GetRoomWebhookDeliveriesParams{RoomID: roomID, Limit: int32(limit)})
+	if err != nil {
+		slog.Error("failed to get webhook deliveries", "error", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []pgstore.WebhookDelivery{}
+	}
+
+	sendJSON(w, deliveries) // Envia o registro de entregas como resposta
+}
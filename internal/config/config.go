@@ -0,0 +1,305 @@
+// Package config carrega a configuração do servidor a partir de valores padrão, de um arquivo opcional (YAML ou TOML),
+// de variáveis de ambiente e de flags de linha de comando, nesta ordem de precedência crescente.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config é a configuração completa do servidor.
+type Config struct {
+	ListenAddr string         `yaml:"listen_addr" toml:"listen_addr"`
+	Database   DatabaseConfig `yaml:"database" toml:"database"`
+	HTTP       HTTPConfig     `yaml:"http" toml:"http"`
+	TLS        TLSConfig      `yaml:"tls" toml:"tls"`
+	Limits     LimitsConfig   `yaml:"limits" toml:"limits"`
+	Webhooks   WebhooksConfig `yaml:"webhooks" toml:"webhooks"`
+
+	// Origens aceitas pelo CORS e pelo upgrade de WebSocket, como "https://app.example.com" ou "https://*.example.com".
+	// Vazio aceita apenas a mesma origem do servidor; "*" aceita qualquer origem.
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+
+	// Chave que assina os tokens de participante, com pelo menos 32 caracteres. Deve ser a mesma em todas as instâncias;
+	// vazia usa uma chave aleatória, e os tokens emitidos deixam de valer quando o servidor reinicia.
+	ParticipantKey string `yaml:"participant_key" toml:"participant_key"`
+
+	FilterWordlist  string `yaml:"filter_wordlist" toml:"filter_wordlist"`   // Arquivo com a lista padrão do filtro; vazio usa a lista embutida
+	TracingExporter string `yaml:"tracing_exporter" toml:"tracing_exporter"` // Um dos TracingExporter*
+}
+
+// DatabaseConfig descreve a conexão com o PostgreSQL.
+// Se URL estiver preenchida, ela tem precedência sobre os campos individuais.
+type DatabaseConfig struct {
+	URL      string `yaml:"url" toml:"url"`
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	Name     string `yaml:"name" toml:"name"`
+	MaxConns int32  `yaml:"max_conns" toml:"max_conns"` // Zero mantém o padrão do pgxpool
+	MinConns int32  `yaml:"min_conns" toml:"min_conns"`
+}
+
+// HTTPConfig reúne os prazos do servidor HTTP.
+type HTTPConfig struct {
+	ReadHeaderTimeout   time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	ReadTimeout         time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout        time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout         time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownGracePeriod time.Duration `yaml:"shutdown_grace_period" toml:"shutdown_grace_period"`
+	ReadinessTimeout    time.Duration `yaml:"readiness_timeout" toml:"readiness_timeout"`
+}
+
+// TLSConfig habilita o HTTPS (com HTTP/2) quando CertFile e KeyFile estão preenchidos.
+// Os arquivos são recarregados ao receber SIGHUP ou quando são alterados, sem derrubar as conexões abertas.
+type TLSConfig struct {
+	CertFile       string        `yaml:"cert_file" toml:"cert_file"`
+	KeyFile        string        `yaml:"key_file" toml:"key_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval" toml:"reload_interval"` // Intervalo de verificação dos arquivos; zero desativa
+	RedirectAddr   string        `yaml:"redirect_addr" toml:"redirect_addr"`     // Endereço HTTP que redireciona para o HTTPS; vazio desativa
+}
+
+// Enabled indica se o servidor deve atender em HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// LimitsConfig reúne os limites aplicados aos clientes.
+type LimitsConfig struct {
+	RateLimit       RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	ReportThreshold int             `yaml:"report_threshold" toml:"report_threshold"`
+}
+
+// WebhooksConfig controla a entrega dos eventos aos webhooks cadastrados nas salas.
+type WebhooksConfig struct {
+	Timeout              time.Duration `yaml:"timeout" toml:"timeout"`                               // Prazo de cada requisição ao receptor
+	MaxAttempts          int           `yaml:"max_attempts" toml:"max_attempts"`                     // Tentativas antes de a entrega ser marcada como falha
+	AllowPrivateNetworks bool          `yaml:"allow_private_networks" toml:"allow_private_networks"` // Permite URLs em endereços privados ou de loopback
+}
+
+// Exportadores de spans aceitos em TracingExporter, repassados a tracing.Setup.
+const (
+	TracingExporterNone   = ""
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// minParticipantKeyLength é o tamanho mínimo da chave dos tokens de participante.
+const minParticipantKeyLength = 32
+
+// Default retorna a configuração usada quando nenhuma fonte sobrescreve os valores.
+func Default() Config {
+	// Os mapas são copiados para que a leitura do arquivo não altere os valores padrão
+	rateLimit := DefaultRateLimitConfig
+	rateLimit.Routes = maps.Clone(rateLimit.Routes)
+	rateLimit.Rooms = maps.Clone(rateLimit.Rooms)
+
+	return Config{
+		ListenAddr: ":8080",
+		Database: DatabaseConfig{
+			Host: "localhost",
+			Port: "5432",
+		},
+		HTTP: HTTPConfig{
+			ReadHeaderTimeout:   10 * time.Second,
+			ReadTimeout:         30 * time.Second,
+			WriteTimeout:        60 * time.Second,
+			IdleTimeout:         120 * time.Second,
+			ShutdownGracePeriod: 15 * time.Second,
+			ReadinessTimeout:    DefaultReadinessTimeout,
+		},
+		TLS: TLSConfig{
+			ReloadInterval: time.Minute,
+		},
+		Limits: LimitsConfig{
+			RateLimit:       rateLimit,
+			ReportThreshold: DefaultReportThreshold,
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:     DefaultWebhookTimeout,
+			MaxAttempts: DefaultWebhookMaxAttempts,
+		},
+	}
+}
+
+// DSN retorna a string de conexão com o banco de dados.
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	return fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s", d.User, d.Password, d.Host, d.Port, d.Name)
+}
+
+// Load monta a configuração a partir das fontes disponíveis.
+// args são os argumentos de linha de comando (sem o nome do programa) e getenv é usado para ler as variáveis de ambiente.
+func Load(args []string, getenv func(string) string) (Config, error) {
+	cfg := Default()
+
+	// As flags são aplicadas por último, depois do arquivo e das variáveis de ambiente
+	var (
+		configPath string
+		pending    []func() error
+	)
+	fs := flag.NewFlagSet("wsrs", flag.ContinueOnError)
+	fs.StringVar(&configPath, "config", getenv("WSRS_CONFIG"), "arquivo de configuração YAML ou TOML (env WSRS_CONFIG)")
+	for _, f := range fields {
+		fs.Func(f.flag, f.usage+" (env "+f.env+")", func(v string) error {
+			pending = append(pending, func() error { return f.set(&cfg, v) })
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if configPath != "" {
+		if err := loadFile(&cfg, configPath); err != nil {
+			return Config{}, fmt.Errorf("config file %s: %w", configPath, err)
+		}
+	}
+
+	for _, f := range fields {
+		if v := getenv(f.env); v != "" {
+			if err := f.set(&cfg, v); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", f.env, err)
+			}
+		}
+	}
+
+	for _, apply := range pending {
+		if err := apply(); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile decodifica o arquivo de configuração, escolhendo o formato pela extensão.
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".toml":
+		_, err := toml.Decode(string(data), cfg)
+		return err
+	default:
+		return errors.New("unsupported format, use .yaml, .yml or .toml")
+	}
+}
+
+// Validate verifica a consistência da configuração.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.ListenAddr == "" {
+		errs = append(errs, errors.New("listen_addr is required"))
+	}
+
+	if c.Database.URL != "" {
+		if _, err := url.Parse(c.Database.URL); err != nil {
+			errs = append(errs, fmt.Errorf("database.url: %w", err))
+		}
+	} else if c.Database.Host == "" || c.Database.User == "" || c.Database.Name == "" {
+		errs = append(errs, errors.New("database: url or host, user and name are required"))
+	}
+
+	if c.Database.MaxConns < 0 || c.Database.MinConns < 0 {
+		errs = append(errs, errors.New("database: pool sizes must not be negative"))
+	}
+	if c.Database.MaxConns > 0 && c.Database.MinConns > c.Database.MaxConns {
+		errs = append(errs, errors.New("database: min_conns must not exceed max_conns"))
+	}
+
+	durations := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"http.read_header_timeout", c.HTTP.ReadHeaderTimeout},
+		{"http.read_timeout", c.HTTP.ReadTimeout},
+		{"http.write_timeout", c.HTTP.WriteTimeout},
+		{"http.idle_timeout", c.HTTP.IdleTimeout},
+		{"http.shutdown_grace_period", c.HTTP.ShutdownGracePeriod},
+		{"http.readiness_timeout", c.HTTP.ReadinessTimeout},
+		{"tls.reload_interval", c.TLS.ReloadInterval},
+		{"webhooks.timeout", c.Webhooks.Timeout},
+	}
+	for _, d := range durations {
+		if d.d < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", d.name))
+		}
+	}
+	if c.HTTP.ReadinessTimeout == 0 {
+		errs = append(errs, errors.New("http.readiness_timeout is required"))
+	}
+
+	if c.TLS.Enabled() && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("tls: cert_file and key_file must be set together"))
+	}
+	if c.TLS.RedirectAddr != "" && !c.TLS.Enabled() {
+		errs = append(errs, errors.New("tls.redirect_addr requires cert_file and key_file"))
+	}
+
+	if err := ValidateOrigins(c.AllowedOrigins); err != nil {
+		errs = append(errs, fmt.Errorf("allowed_origins: %w", err))
+	}
+
+	if c.Limits.RateLimit.Default.Rate < 0 || c.Limits.RateLimit.Default.Burst < 1 {
+		errs = append(errs, errors.New("limits.rate_limit.default: rate must not be negative and burst must be at least 1"))
+	}
+
+	if c.ParticipantKey != "" && len(c.ParticipantKey) < minParticipantKeyLength {
+		errs = append(errs, fmt.Errorf("participant_key must have at least %d characters", minParticipantKeyLength))
+	}
+
+	if c.Webhooks.MaxAttempts < 1 {
+		errs = append(errs, errors.New("webhooks.max_attempts must be at least 1"))
+	}
+
+	switch c.TracingExporter {
+	case TracingExporterNone, TracingExporterOTLP, TracingExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("tracing_exporter: unknown exporter %q", c.TracingExporter))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Print escreve a configuração em YAML, ocultando a senha do banco de dados e a chave dos tokens de participante.
+func Print(w io.Writer, c Config) error {
+	if c.Database.Password != "" {
+		c.Database.Password = "********"
+	}
+	if c.ParticipantKey != "" {
+		c.ParticipantKey = "********"
+	}
+	if u, err := url.Parse(c.Database.URL); err == nil {
+		c.Database.URL = u.Redacted()
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	defer enc.Close()
+	return enc.Encode(c)
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field associa uma opção de configuração à sua variável de ambiente e à sua flag de linha de comando.
+type field struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+// fields lista as opções que podem ser definidas por variáveis de ambiente e flags.
+// Listas como as regras de limite por rota ou por sala só podem ser definidas no arquivo de configuração.
+var fields = []field{
+	{"WSRS_LISTEN_ADDR", "listen", "endereço de escuta do servidor HTTP", setString(func(c *Config) *string { return &c.ListenAddr })},
+
+	{"DATABASE_URL", "database-url", "URL de conexão com o PostgreSQL (tem precedência sobre os campos individuais)", setString(func(c *Config) *string { return &c.Database.URL })},
+	{"WSRS_DATABASE_HOST", "database-host", "host do PostgreSQL", setString(func(c *Config) *string { return &c.Database.Host })},
+	{"WSRS_DATABASE_PORT", "database-port", "porta do PostgreSQL", setString(func(c *Config) *string { return &c.Database.Port })},
+	{"WSRS_DATABASE_USER", "database-user", "usuário do PostgreSQL", setString(func(c *Config) *string { return &c.Database.User })},
+	{"WSRS_DATABASE_PASSWORD", "database-password", "senha do PostgreSQL", setString(func(c *Config) *string { return &c.Database.Password })},
+	{"WSRS_DATABASE_NAME", "database-name", "nome do banco de dados", setString(func(c *Config) *string { return &c.Database.Name })},
+	{"WSRS_DATABASE_MAX_CONNS", "database-max-conns", "número máximo de conexões da pool", setInt32(func(c *Config) *int32 { return &c.Database.MaxConns })},
+	{"WSRS_DATABASE_MIN_CONNS", "database-min-conns", "número mínimo de conexões da pool", setInt32(func(c *Config) *int32 { return &c.Database.MinConns })},
+
+	{"WSRS_HTTP_READ_HEADER_TIMEOUT", "http-read-header-timeout", "prazo para a leitura dos cabeçalhos", setDuration(func(c *Config) *time.Duration { return &c.HTTP.ReadHeaderTimeout })},
+	{"WSRS_HTTP_READ_TIMEOUT", "http-read-timeout", "prazo para a leitura da requisição", setDuration(func(c *Config) *time.Duration { return &c.HTTP.ReadTimeout })},
+	{"WSRS_HTTP_WRITE_TIMEOUT", "http-write-timeout", "prazo para a escrita da resposta", setDuration(func(c *Config) *time.Duration { return &c.HTTP.WriteTimeout })},
+	{"WSRS_HTTP_IDLE_TIMEOUT", "http-idle-timeout", "prazo de conexões keep-alive ociosas", setDuration(func(c *Config) *time.Duration { return &c.HTTP.IdleTimeout })},
+	{"WSRS_SHUTDOWN_GRACE_PERIOD", "shutdown-grace-period", "período de tolerância do encerramento", setDuration(func(c *Config) *time.Duration { return &c.HTTP.ShutdownGracePeriod })},
+	{"WSRS_READINESS_TIMEOUT", "readiness-timeout", "prazo de cada verificação de /readyz", setDuration(func(c *Config) *time.Duration { return &c.HTTP.ReadinessTimeout })},
+
+	{"WSRS_TLS_CERT_FILE", "tls-cert-file", "arquivo do certificado TLS (PEM)", setString(func(c *Config) *string { return &c.TLS.CertFile })},
+	{"WSRS_TLS_KEY_FILE", "tls-key-file", "arquivo da chave privada TLS (PEM)", setString(func(c *Config) *string { return &c.TLS.KeyFile })},
+	{"WSRS_TLS_RELOAD_INTERVAL", "tls-reload-interval", "intervalo de verificação dos arquivos TLS (0 desativa)", setDuration(func(c *Config) *time.Duration { return &c.TLS.ReloadInterval })},
+	{"WSRS_TLS_REDIRECT_ADDR", "tls-redirect-addr", "endereço HTTP que redireciona para o HTTPS", setString(func(c *Config) *string { return &c.TLS.RedirectAddr })},
+
+	{"WSRS_ALLOWED_ORIGINS", "allowed-origins", "origens aceitas pelo CORS e pelo WebSocket, separadas por vírgula", setList(func(c *Config) *[]string { return &c.AllowedOrigins })},
+
+	{"WSRS_RATE_LIMIT_RATE", "rate-limit-rate", "tokens repostos por segundo no limite padrão", setFloat(func(c *Config) *float64 { return &c.Limits.RateLimit.Default.Rate })},
+	{"WSRS_RATE_LIMIT_BURST", "rate-limit-burst", "capacidade do balde no limite padrão", setInt(func(c *Config) *int { return &c.Limits.RateLimit.Default.Burst })},
+	{"WSRS_REPORT_THRESHOLD", "report-threshold", "denúncias que ocultam uma mensagem (0 desativa)", setInt(func(c *Config) *int { return &c.Limits.ReportThreshold })},
+
+	{"WSRS_WEBHOOK_TIMEOUT", "webhook-timeout", "prazo de cada entrega de webhook", setDuration(func(c *Config) *time.Duration { return &c.Webhooks.Timeout })},
+	{"WSRS_WEBHOOK_MAX_ATTEMPTS", "webhook-max-attempts", "tentativas de entrega de um webhook antes de desistir", setInt(func(c *Config) *int { return &c.Webhooks.MaxAttempts })},
+	{"WSRS_WEBHOOK_ALLOW_PRIVATE_NETWORKS", "webhook-allow-private-networks", "permite webhooks em endereços privados ou de loopback", setBool(func(c *Config) *bool { return &c.Webhooks.AllowPrivateNetworks })},
+
+	{"WSRS_PARTICIPANT_KEY", "participant-key", "chave que assina os tokens de participante", setString(func(c *Config) *string { return &c.ParticipantKey })},
+
+	{"WSRS_FILTER_WORDLIST", "filter-wordlist", "arquivo com a lista padrão do filtro de conteúdo", setString(func(c *Config) *string { return &c.FilterWordlist })},
+	{"WSRS_TRACING_EXPORTER", "tracing-exporter", `exportador de spans ("otlp" ou "stdout")`, setString(func(c *Config) *string { return &c.TracingExporter })},
+}
+
+func setString(get func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*get(c) = v
+		return nil
+	}
+}
+
+func setInt(get func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		*get(c) = n
+		return err
+	}
+}
+
+func setInt32(get func(*Config) *int32) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 32)
+		*get(c) = int32(n)
+		return err
+	}
+}
+
+func setBool(get func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		*get(c) = b
+		return err
+	}
+}
+
+func setFloat(get func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.ParseFloat(v, 64)
+		*get(c) = n
+		return err
+	}
+}
+
+func setDuration(get func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		*get(c) = d
+		return err
+	}
+}
+
+func setList(get func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*get(c) = items
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import "time"
+
+// RateLimit descreve um balde de tokens: até Burst requisições imediatas, repostas à taxa de Rate tokens por segundo.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate" toml:"rate"`   // Tokens repostos por segundo
+	Burst int     `yaml:"burst" toml:"burst"` // Capacidade máxima do balde
+}
+
+// RateLimitConfig define os limites aplicados às rotas de escrita.
+// A precedência é: limite da sala, limite da rota e, por fim, o limite padrão.
+type RateLimitConfig struct {
+	Default RateLimit            `yaml:"default" toml:"default"` // Limite usado quando não há configuração mais específica
+	Routes  map[string]RateLimit `yaml:"routes" toml:"routes"`   // Limites por rota, chave no formato "POST /api/rooms/{room_id}/messages"
+	Rooms   map[string]RateLimit `yaml:"rooms" toml:"rooms"`     // Limites por sala, chave é o ID da sala
+}
+
+// DefaultRateLimitConfig é a configuração de limites de requisições usada quando nenhuma fonte a sobrescreve.
+var DefaultRateLimitConfig = RateLimitConfig{
+	Default: RateLimit{Rate: 2, Burst: 10},
+	Routes: map[string]RateLimit{
+		"POST /api/rooms":                    {Rate: 0.1, Burst: 3},
+		"POST /api/rooms/import":             {Rate: 0.1, Burst: 3},
+		"POST /api/rooms/{room_id}/clone":    {Rate: 0.1, Burst: 3},
+		"POST /api/rooms/{room_id}/messages": {Rate: 0.2, Burst: 5},
+	},
+}
+
+// DefaultReportThreshold é o número de denúncias a partir do qual uma mensagem é ocultada automaticamente.
+const DefaultReportThreshold = 5
+
+// DefaultReadinessTimeout é o tempo máximo de cada verificação de dependência em /readyz.
+const DefaultReadinessTimeout = 2 * time.Second
+
+// Valores padrão da entrega de webhooks, repassados a webhook.NewDispatcher.
+const (
+	DefaultWebhookTimeout     = 10 * time.Second // Prazo de cada requisição ao receptor
+	DefaultWebhookMaxAttempts = 10               // Tentativas antes de a entrega ser marcada como falha
+)
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// originPattern é uma origem aceita, já decomposta para a comparação.
+// O host pode começar com "*." para aceitar qualquer subdomínio (mas não o próprio domínio).
+type originPattern struct {
+	scheme string
+	host   string // Host em minúsculas, sem o prefixo "*."
+	port   string
+	sub    bool // Aceita apenas subdomínios de host
+}
+
+// parseOriginPattern interpreta um padrão de origem no formato scheme://host[:port], como "https://*.example.com".
+func parseOriginPattern(pattern string) (originPattern, error) {
+	u, err := url.Parse(pattern)
+	if err != nil {
+		return originPattern{}, fmt.Errorf("invalid origin %q: %w", pattern, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+		return originPattern{}, fmt.Errorf("invalid origin %q: expected scheme://host[:port]", pattern)
+	}
+
+	p := originPattern{scheme: u.Scheme, host: strings.ToLower(u.Hostname()), port: u.Port()}
+	if host, ok := strings.CutPrefix(p.host, "*."); ok {
+		p.host, p.sub = host, true
+	}
+	if p.host == "" || strings.Contains(p.host, "*") {
+		return originPattern{}, fmt.Errorf("invalid origin %q: wildcards are only allowed as the first label", pattern)
+	}
+	return p, nil
+}
+
+// matches verifica se a origem (já decomposta) corresponde ao padrão.
+func (p originPattern) matches(scheme, host, port string) bool {
+	if scheme != p.scheme || port != p.port {
+		return false
+	}
+	if p.sub {
+		return strings.HasSuffix(host, "."+p.host)
+	}
+	return host == p.host
+}
+
+// Origins é a lista de origens aceitas, compartilhada pelo CORS e pelo upgrade de WebSocket.
+// O valor zero não aceita nenhuma origem.
+type Origins struct {
+	any      bool // "*" aceita qualquer origem
+	patterns []originPattern
+}
+
+// ParseOrigins interpreta os padrões de origem aceitos.
+// Cada padrão tem o formato scheme://host[:port]; "https://*.example.com" aceita qualquer subdomínio de example.com
+// e "*" aceita qualquer origem.
+func ParseOrigins(patterns []string) (Origins, error) {
+	var list Origins
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			list.any = true
+			continue
+		}
+
+		p, err := parseOriginPattern(pattern)
+		if err != nil {
+			return Origins{}, err
+		}
+		list.patterns = append(list.patterns, p)
+	}
+	return list, nil
+}
+
+// ValidateOrigins verifica os padrões de origem aceitos por ParseOrigins.
+func ValidateOrigins(patterns []string) error {
+	_, err := ParseOrigins(patterns)
+	return err
+}
+
+// Allowed verifica se o valor do cabeçalho Origin está na lista.
+func (l Origins) Allowed(origin string) bool {
+	if l.any {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme, host, port := u.Scheme, strings.ToLower(u.Hostname()), u.Port()
+	return slices.ContainsFunc(l.patterns, func(p originPattern) bool {
+		return p.matches(scheme, host, port)
+	})
+}
@@ -0,0 +1,68 @@
+package config
+
+import "testing"
+
+func TestParseOrigins(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"*", false},
+		{"https://example.com", false},
+		{"http://localhost:3000", false},
+		{"https://*.example.com", false},
+		{"https://example.com/", false},
+		{"example.com", true},
+		{"ftp://example.com", true},
+		{"https://example.com/path", true},
+		{"https://example.com?query", true},
+		{"https://user@example.com", true},
+		{"https://*", true},
+		{"https://*.*.example.com", true},
+		{"https://app.*.example.com", true},
+		{"https://*example.com", true},
+	}
+	for _, tt := range tests {
+		if _, err := ParseOrigins([]string{tt.pattern}); (err != nil) != tt.wantErr {
+			t.Errorf("ParseOrigins(%q) error = %v, want error %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOriginsAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		origin   string
+		want     bool
+	}{
+		{"exact", []string{"https://example.com"}, "https://example.com", true},
+		{"host is case-insensitive", []string{"https://Example.com"}, "https://EXAMPLE.com", true},
+		{"other host", []string{"https://example.com"}, "https://example.org", false},
+		{"other scheme", []string{"https://example.com"}, "http://example.com", false},
+		{"port must match", []string{"http://localhost:3000"}, "http://localhost:3001", false},
+		{"missing port", []string{"http://localhost:3000"}, "http://localhost", false},
+		{"explicit port", []string{"http://localhost:3000"}, "http://localhost:3000", true},
+		{"subdomain", []string{"https://*.example.com"}, "https://app.example.com", true},
+		{"nested subdomain", []string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{"wildcard does not match the domain itself", []string{"https://*.example.com"}, "https://example.com", false},
+		{"wildcard does not match a suffix", []string{"https://*.example.com"}, "https://badexample.com", false},
+		{"wildcard does not match another domain", []string{"https://*.example.com"}, "https://example.com.evil.org", false},
+		{"any of the patterns", []string{"https://example.org", "https://*.example.com"}, "https://app.example.com", true},
+		{"any origin", []string{"*"}, "https://anything.example", true},
+		{"no patterns", nil, "https://example.com", false},
+		{"null origin", []string{"https://example.com"}, "null", false},
+		{"empty origin", []string{"https://example.com"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origins, err := ParseOrigins(tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := origins.Allowed(tt.origin); got != tt.want {
+				t.Errorf("Allowed(%q) with %q = %v, want %v", tt.origin, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Package filter implementa o filtro de conteúdo aplicado às mensagens antes de serem gravadas.
+package filter
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Action indica o que fazer com uma mensagem que corresponde a uma regra.
+type Action string
+
+// Ações possíveis, da menos para a mais restritiva
+const (
+	ActionAllow    Action = "allow"    // A mensagem é gravada sem alterações
+	ActionMask     Action = "mask"     // Os trechos encontrados são substituídos por asteriscos
+	ActionModerate Action = "moderate" // A mensagem é enviada para a fila de moderação
+	ActionReject   Action = "reject"   // A mensagem é recusada
+)
+
+// severity ordena as ações para que a mais restritiva prevaleça.
+var severity = map[Action]int{
+	ActionAllow:    0,
+	ActionMask:     1,
+	ActionModerate: 2,
+	ActionReject:   3,
+}
+
+// ParseAction converte uma string em Action, aceitando apenas as ações que podem ser associadas a regras.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(s); a {
+	case ActionMask, ActionModerate, ActionReject:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid filter action %q", s)
+}
+
+// Rule é uma palavra ou expressão regular associada a uma ação.
+type Rule struct {
+	Pattern string // Palavra (comparada sem diferenciar maiúsculas) ou expressão regular
+	Regex   bool   // Indica se Pattern é uma expressão regular
+	Action  Action // Ação aplicada quando a regra corresponde
+}
+
+// wordBoundary delimita uma palavra inteira. Ao contrário de \b, que só considera letras ASCII,
+// trata letras acentuadas como parte da palavra ("ação" não corresponde a "a").
+const wordBoundary = `[^\p{L}\p{N}_]`
+
+// Compile valida a regra e retorna a expressão regular equivalente.
+// Palavras são comparadas por palavra inteira e sem diferenciar maiúsculas de minúsculas;
+// nesse caso, o trecho correspondente é o primeiro grupo da expressão.
+func (r Rule) Compile() (*regexp.Regexp, error) {
+	if r.Regex {
+		return regexp.Compile(r.Pattern)
+	}
+	return regexp.Compile(`(?i)(?:^|` + wordBoundary + `)(` + regexp.QuoteMeta(r.Pattern) + `)(?:` + wordBoundary + `|$)`)
+}
+
+// Result é o resultado da avaliação de uma mensagem.
+type Result struct {
+	Action  Action   // Ação mais restritiva entre as regras que corresponderam
+	Text    string   // Texto da mensagem, com os trechos mascarados quando houver regras de máscara
+	Matches []string // Trechos que corresponderam a alguma regra
+}
+
+// Filter avalia o texto de uma mensagem enviada para uma sala.
+type Filter interface {
+	Check(ctx context.Context, roomID uuid.UUID, text string) (Result, error)
+	// Defaults retorna as regras padrão, para avaliar mensagens que ainda não pertencem a uma sala.
+	Defaults() RuleSet
+}
+
+// RuleSet é um conjunto de regras já compiladas, que avalia várias mensagens sem consultar RuleStore a cada uma.
+type RuleSet []compiledRule
+
+// Check avalia o texto com as regras do conjunto.
+func (s RuleSet) Check(text string) Result {
+	return apply(s, text)
+}
+
+// RuleStore fornece as regras específicas de cada sala.
+type RuleStore interface {
+	GetRoomFilterRules(ctx context.Context, roomID uuid.UUID) ([]pgstore.RoomFilterRule, error)
+}
+
+// maxCachedRooms é o número máximo de salas com as regras compiladas em cache.
+const maxCachedRooms = 1024
+
+// compiledRule é uma regra com a sua expressão regular.
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// find retorna as posições dos trechos do texto que correspondem à regra.
+func (c compiledRule) find(text string) [][2]int {
+	var spans [][2]int
+	if c.Regex {
+		for _, loc := range c.re.FindAllStringIndex(text, -1) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+		return spans
+	}
+
+	// Os delimitadores fazem parte da correspondência; a busca recomeça logo após a palavra,
+	// para que o delimitador entre duas palavras seguidas sirva às duas
+	for start := 0; start < len(text); {
+		loc := c.re.FindStringSubmatchIndex(text[start:])
+		if loc == nil {
+			break
+		}
+		spans = append(spans, [2]int{start + loc[2], start + loc[3]})
+		start += max(loc[3], loc[2]+1)
+	}
+	return spans
+}
+
+// roomRules guarda as regras de uma sala e as suas expressões compiladas.
+type roomRules struct {
+	rules    []Rule
+	compiled []compiledRule
+}
+
+// listFilter aplica as regras padrão e as regras de cada sala armazenadas em RuleStore.
+type listFilter struct {
+	store    RuleStore
+	defaults []compiledRule
+	rooms    map[uuid.UUID]roomRules // Regras compiladas de cada sala, refeitas quando as regras mudam
+	mu       sync.Mutex
+}
+
+// New cria um Filter que combina as regras padrão com as regras de cada sala.
+// store pode ser nil, caso em que apenas as regras padrão são aplicadas.
+func New(store RuleStore, defaults []Rule) Filter {
+	return &listFilter{
+		store:    store,
+		defaults: compileRules(defaults),
+		rooms:    make(map[uuid.UUID]roomRules),
+	}
+}
+
+// compileRules compila as regras, ignorando as inválidas, que são recusadas na criação.
+func compileRules(rules []Rule) []compiledRule {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		re, err := rule.Compile()
+		if err != nil {
+			continue
+		}
+		compiled = append(compiled, compiledRule{Rule: rule, re: re})
+	}
+	return compiled
+}
+
+// Check avalia o texto com as regras padrão e as regras da sala.
+func (f *listFilter) Check(ctx context.Context, roomID uuid.UUID, text string) (Result, error) {
+	rules := f.defaults
+
+	if f.store != nil {
+		roomRules, err := f.store.GetRoomFilterRules(ctx, roomID)
+		if err != nil {
+			return Result{}, err
+		}
+
+		current := make([]Rule, 0, len(roomRules))
+		for _, rr := range roomRules {
+			current = append(current, Rule{Pattern: rr.Pattern, Regex: rr.IsRegex, Action: Action(rr.Action)})
+		}
+		rules = append(slices.Clip(rules), f.compileRoom(roomID, current)...)
+	}
+
+	return apply(rules, text), nil
+}
+
+// Defaults retorna as regras padrão do filtro.
+func (f *listFilter) Defaults() RuleSet {
+	return f.defaults
+}
+
+// compileRoom retorna as regras compiladas da sala, refazendo o cache da sala se as regras mudaram desde a última avaliação.
+// As expressões de regras removidas ou substituídas são descartadas junto com o cache anterior.
+func (f *listFilter) compileRoom(roomID uuid.UUID, rules []Rule) []compiledRule {
+	if len(rules) == 0 {
+		f.mu.Lock()
+		delete(f.rooms, roomID)
+		f.mu.Unlock()
+		return nil
+	}
+
+	f.mu.Lock()
+	cached, ok := f.rooms[roomID]
+	f.mu.Unlock()
+	if ok && slices.Equal(cached.rules, rules) {
+		return cached.compiled
+	}
+
+	compiled := compileRules(rules)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.rooms[roomID]; !ok && len(f.rooms) >= maxCachedRooms {
+		clear(f.rooms) // Limita a memória usada pelas salas que deixaram de receber mensagens
+	}
+	f.rooms[roomID] = roomRules{rules: rules, compiled: compiled}
+	return compiled
+}
+
+// apply avalia o texto com as regras informadas.
+func apply(rules []compiledRule, text string) Result {
+	result := Result{Action: ActionAllow, Text: text}
+
+	for _, rule := range rules {
+		spans := rule.find(text)
+		if len(spans) == 0 {
+			continue
+		}
+
+		for _, span := range spans {
+			result.Matches = append(result.Matches, text[span[0]:span[1]])
+		}
+		if severity[rule.Action] > severity[result.Action] {
+			result.Action = rule.Action
+		}
+
+		if rule.Action == ActionMask {
+			result.Text = maskSpans(result.Text, rule.find(result.Text))
+		}
+	}
+
+	return result
+}
+
+// maskSpans substitui os trechos do texto nas posições informadas por asteriscos.
+func maskSpans(text string, spans [][2]int) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		b.WriteString(text[last:span[0]])
+		b.WriteString(mask(text[span[0]:span[1]]))
+		last = span[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// mask substitui cada caractere do trecho por um asterisco.
+func mask(s string) string {
+	return strings.Repeat("*", len([]rune(s)))
+}
+
+//go:embed wordlist.txt
+var defaultWordlist string
+
+// DefaultRules retorna a lista de regras padrão embutida no binário (wordlist.txt).
+func DefaultRules() []Rule {
+	rules, err := Parse(strings.NewReader(defaultWordlist))
+	if err != nil {
+		panic(err) // A lista embutida é validada em tempo de desenvolvimento
+	}
+	return rules
+}
+
+// LoadFile carrega uma lista de regras padrão de um arquivo.
+// Cada linha contém uma regra no formato "[ação:]padrão"; padrões entre barras (/.../) são expressões regulares.
+// Linhas vazias ou iniciadas por # são ignoradas e a ação padrão é mask.
+func LoadFile(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse lê uma lista de regras no formato aceito por LoadFile.
+func Parse(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		rule := Rule{Pattern: text, Action: ActionMask}
+		if prefix, rest, ok := strings.Cut(text, ":"); ok {
+			if action, err := ParseAction(prefix); err == nil {
+				rule.Action, rule.Pattern = action, strings.TrimSpace(rest)
+			}
+		}
+
+		if len(rule.Pattern) > 2 && strings.HasPrefix(rule.Pattern, "/") && strings.HasSuffix(rule.Pattern, "/") {
+			rule.Pattern, rule.Regex = rule.Pattern[1:len(rule.Pattern)-1], true
+		}
+
+		if _, err := rule.Compile(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
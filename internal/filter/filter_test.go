@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Rule
+		wantErr bool
+	}{
+		{"word with the default action", "spam", []Rule{{Pattern: "spam", Action: ActionMask}}, false},
+		{"action prefix", "reject: spam ", []Rule{{Pattern: "spam", Action: ActionReject}}, false},
+		{"regex", "moderate:/sp[a4]m/", []Rule{{Pattern: "sp[a4]m", Regex: true, Action: ActionModerate}}, false},
+		{"unknown prefix is part of the pattern", "http://example.com", []Rule{{Pattern: "http://example.com", Action: ActionMask}}, false},
+		{"allow is not a rule action", "allow:spam", []Rule{{Pattern: "allow:spam", Action: ActionMask}}, false},
+		{"lone slashes are a word", "//", []Rule{{Pattern: "//", Action: ActionMask}}, false},
+		{"comments and blank lines", "# comment\n\n  \nspam\n", []Rule{{Pattern: "spam", Action: ActionMask}}, false},
+		{"invalid regex", "spam\nreject:/(/", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReportsLine(t *testing.T) {
+	_, err := Parse(strings.NewReader("spam\n\nreject:/(/"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("Parse error = %v, want it on line 3", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	rules := []Rule{
+		{Pattern: "spam", Action: ActionMask},
+		{Pattern: "ação", Action: ActionModerate},
+		{Pattern: "c++", Action: ActionMask},
+		{Pattern: `\d{3}-\d{4}`, Regex: true, Action: ActionReject},
+	}
+	tests := []struct {
+		text        string
+		wantAction  Action
+		wantText    string
+		wantMatches []string
+	}{
+		{"hello", ActionAllow, "hello", nil},
+		{"spam", ActionMask, "****", []string{"spam"}},
+		{"Spam, SPAM!", ActionMask, "****, ****!", []string{"Spam", "SPAM"}},
+		{"spam spam", ActionMask, "**** ****", []string{"spam", "spam"}}, // O espaço entre as palavras delimita as duas
+		{"spammer", ActionAllow, "spammer", nil},
+		{"antispam", ActionAllow, "antispam", nil},
+		{"spam_bot", ActionAllow, "spam_bot", nil},
+		{"spamé", ActionAllow, "spamé", nil}, // Letras acentuadas fazem parte da palavra
+		{"Ação!", ActionModerate, "Ação!", []string{"Ação"}},
+		{"reação", ActionAllow, "reação", nil},
+		{"learn c++ now", ActionMask, "learn *** now", []string{"c++"}},
+		{"call 555-1234 spam", ActionReject, "call 555-1234 ****", []string{"spam", "555-1234"}}, // Prevalece a ação mais restritiva
+	}
+	f := New(nil, rules)
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := f.Check(context.Background(), uuid.New(), tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Action != tt.wantAction || got.Text != tt.wantText || !slices.Equal(got.Matches, tt.wantMatches) {
+				t.Errorf("Check(%q) = %+v, want {Action:%s Text:%s Matches:%q}", tt.text, got, tt.wantAction, tt.wantText, tt.wantMatches)
+			}
+		})
+	}
+}
+
+// roomStore é um RuleStore com as mesmas regras em todas as salas, que conta as consultas.
+type roomStore struct {
+	rules []pgstore.RoomFilterRule
+	calls int
+}
+
+func (s *roomStore) GetRoomFilterRules(ctx context.Context, roomID uuid.UUID) ([]pgstore.RoomFilterRule, error) {
+	s.calls++
+	return s.rules, nil
+}
+
+func TestDefaults(t *testing.T) {
+	store := &roomStore{rules: []pgstore.RoomFilterRule{{Pattern: "ham", Action: string(ActionReject)}}}
+	f := New(store, []Rule{{Pattern: "spam", Action: ActionMask}})
+
+	rules := f.Defaults()
+	for _, text := range []string{"spam", "ham", "spam and ham"} {
+		want, err := New(nil, []Rule{{Pattern: "spam", Action: ActionMask}}).Check(context.Background(), uuid.New(), text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rules.Check(text); got.Action != want.Action || got.Text != want.Text {
+			t.Errorf("Defaults().Check(%q) = %+v, want %+v", text, got, want)
+		}
+	}
+	if store.calls != 0 {
+		t.Errorf("Defaults queried the room rules %d times", store.calls)
+	}
+
+	// Check combina as regras padrão com as da sala
+	if got, err := f.Check(context.Background(), uuid.New(), "spam and ham"); err != nil || got.Action != ActionReject || got.Text != "**** and ham" {
+		t.Errorf("Check = %+v, %v, want the room rule to reject", got, err)
+	}
+}
+
+func TestDefaultRules(t *testing.T) {
+	if len(DefaultRules()) == 0 {
+		t.Error("embedded wordlist has no rules")
+	}
+}
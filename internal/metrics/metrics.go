@@ -0,0 +1,113 @@
+// Package metrics reúne as métricas Prometheus expostas pelo servidor em /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry é o registro onde todas as métricas do servidor são cadastradas.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests conta as requisições HTTP por método, rota (padrão do chi) e código de status.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "wsrs",
+		Name:      "http_requests_total",
+		Help:      "Total de requisições HTTP por método, rota e código de status.",
+	}, []string{"method", "route", "code"})
+
+	// HTTPRequestDuration mede a latência das requisições HTTP por método e rota.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "wsrs",
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP por método e rota.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// WebSocketConnections mede as conexões WebSocket ativas por sala.
+	WebSocketConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "wsrs",
+		Name:      "websocket_connections",
+		Help:      "Conexões WebSocket ativas por sala.",
+	}, []string{"room_id"})
+
+	// BroadcastDuration mede quanto tempo leva o envio de um evento a todos os assinantes de uma sala.
+	BroadcastDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "wsrs",
+		Name:      "broadcast_duration_seconds",
+		Help:      "Duração do envio de um evento a todos os assinantes da sala, por tipo de evento.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"kind"})
+
+	// BroadcastMessagesSent conta os eventos entregues aos assinantes.
+	BroadcastMessagesSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "wsrs",
+		Name:      "broadcast_messages_sent_total",
+		Help:      "Total de eventos entregues a conexões WebSocket, por tipo de evento.",
+	}, []string{"kind"})
+
+	// BroadcastFailedWrites conta os eventos que não puderam ser entregues e derrubaram a conexão.
+	BroadcastFailedWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "wsrs",
+		Name:      "broadcast_failed_writes_total",
+		Help:      "Total de escritas em conexões WebSocket que falharam, por tipo de evento.",
+	}, []string{"kind"})
+
+	// WebhookDeliveries conta as tentativas de entrega de webhooks por resultado ("delivered", "retry" ou "failed").
+	WebhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "wsrs",
+		Name:      "webhook_deliveries_total",
+		Help:      "Total de tentativas de entrega de webhooks, por resultado.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		WebSocketConnections,
+		BroadcastDuration,
+		BroadcastMessagesSent,
+		BroadcastFailedWrites,
+		WebhookDeliveries,
+	)
+}
+
+// Handler retorna o handler HTTP que expõe as métricas no formato do Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware registra a contagem e a latência das requisições HTTP.
+// As rotas são identificadas pelo padrão do chi (ex.: /api/rooms/{room_id}) para manter a cardinalidade baixa.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,42 @@
+package pgstore
+
+import (
+	"embed"
+	"io/fs"
+	"strings"
+)
+
+// migrations contém os arquivos de migração aplicados pelo tern.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationSeparator separa, em cada arquivo do tern, o trecho que cria o esquema do trecho que o desfaz.
+const migrationSeparator = "---- create above / drop below ----"
+
+// SchemaVersion retorna a versão do esquema esperada pelo código, isto é, o número de migrações existentes.
+// O tern registra a versão aplicada na tabela schema_version.
+func SchemaVersion() int {
+	entries, _ := fs.Glob(migrations, "migrations/*.sql")
+	return len(entries)
+}
+
+// Migrations retorna, em ordem, o trecho de criação de cada migração.
+// É usada para aplicar o esquema sem o tern, como nos bancos de dados descartáveis dos testes.
+func Migrations() ([]string, error) {
+	entries, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	up := make([]string, 0, len(entries))
+	for _, entry := range entries { // fs.Glob retorna os arquivos em ordem lexical, que é a ordem numérica das migrações
+		data, err := migrations.ReadFile(entry)
+		if err != nil {
+			return nil, err
+		}
+		create, _, _ := strings.Cut(string(data), migrationSeparator)
+		up = append(up, create)
+	}
+	return up, nil
+}
@@ -0,0 +1,17 @@
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS "moderated" BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS "moderation_status" VARCHAR(16) NOT NULL DEFAULT 'approved'
+    CHECK ("moderation_status" IN ('pending', 'approved', 'rejected'));
+
+CREATE TABLE IF NOT EXISTS room_moderator_tokens (
+    "room_id"       uuid            PRIMARY KEY     NOT NULL,
+    "token_hash"    VARCHAR(64)                     NOT NULL,
+
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS room_moderator_tokens;
+ALTER TABLE messages DROP COLUMN IF EXISTS "moderation_status";
+ALTER TABLE rooms DROP COLUMN IF EXISTS "moderated";
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS room_filter_rules (
+    "id"        uuid            PRIMARY KEY     NOT NULL    DEFAULT gen_random_uuid(),
+    "room_id"   uuid                            NOT NULL,
+    "pattern"   VARCHAR(255)                    NOT NULL,
+    "is_regex"  BOOLEAN                         NOT NULL    DEFAULT false,
+    "action"    VARCHAR(16)                     NOT NULL    CHECK ("action" IN ('mask', 'reject', 'moderate')),
+
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS room_filter_rules;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS room_bans (
+    "id"                uuid            PRIMARY KEY     NOT NULL    DEFAULT gen_random_uuid(),
+    "room_id"           uuid                            NOT NULL,
+    "participant_id"    VARCHAR(255)                    NOT NULL    DEFAULT '',
+    "ip_range"          VARCHAR(64)                     NOT NULL    DEFAULT '',
+    "kind"              VARCHAR(8)                      NOT NULL    CHECK ("kind" IN ('ban', 'mute')),
+    "reason"            VARCHAR(255)                    NOT NULL    DEFAULT '',
+    "expires_at"        TIMESTAMPTZ,
+    "created_at"        TIMESTAMPTZ                     NOT NULL    DEFAULT now(),
+
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CHECK ("participant_id" <> '' OR "ip_range" <> '')
+);
+
+CREATE INDEX IF NOT EXISTS room_bans_room_id_idx ON room_bans (room_id);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS room_bans;
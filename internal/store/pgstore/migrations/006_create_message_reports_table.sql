@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS message_reports (
+    "id"            uuid            PRIMARY KEY     NOT NULL    DEFAULT gen_random_uuid(),
+    "message_id"    uuid                            NOT NULL,
+    "reporter"      VARCHAR(255)                    NOT NULL,
+    "reason"        VARCHAR(255)                    NOT NULL    DEFAULT '',
+    "created_at"    TIMESTAMPTZ                     NOT NULL    DEFAULT now(),
+
+    FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+    UNIQUE (message_id, reporter)
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS message_reports;
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS room_webhooks (
+    "id"            uuid            PRIMARY KEY     NOT NULL    DEFAULT gen_random_uuid(),
+    "room_id"       uuid                            NOT NULL,
+    "url"           VARCHAR(2048)                   NOT NULL,
+    "secret"        VARCHAR(64)                     NOT NULL,
+    "event_kinds"   TEXT[]                          NOT NULL    DEFAULT '{}',
+    "created_at"    TIMESTAMPTZ                     NOT NULL    DEFAULT now(),
+
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS room_webhooks_room_id_idx ON room_webhooks (room_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    "id"                uuid            PRIMARY KEY     NOT NULL    DEFAULT gen_random_uuid(),
+    "webhook_id"        uuid                            NOT NULL,
+    "event_kind"        VARCHAR(64)                     NOT NULL,
+    "payload"           TEXT                            NOT NULL,
+    "status"            VARCHAR(16)                     NOT NULL    DEFAULT 'pending'
+        CHECK ("status" IN ('pending', 'delivered', 'failed')),
+    "attempts"          INTEGER                         NOT NULL    DEFAULT 0,
+    "last_status_code"  INTEGER                         NOT NULL    DEFAULT 0,
+    "last_error"        TEXT                            NOT NULL    DEFAULT '',
+    "next_attempt_at"   TIMESTAMPTZ                     NOT NULL    DEFAULT now(),
+    "delivered_at"      TIMESTAMPTZ,
+    "created_at"        TIMESTAMPTZ                     NOT NULL    DEFAULT now(),
+
+    FOREIGN KEY (webhook_id) REFERENCES room_webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at DESC);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS room_webhooks;
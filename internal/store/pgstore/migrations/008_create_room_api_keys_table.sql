@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS room_api_keys (
+    "id"            uuid            PRIMARY KEY     NOT NULL    DEFAULT gen_random_uuid(),
+    "room_id"       uuid                            NOT NULL,
+    "name"          VARCHAR(64)                     NOT NULL,
+    "key_hash"      VARCHAR(64)                     NOT NULL    UNIQUE,
+    "created_at"    TIMESTAMPTZ                     NOT NULL    DEFAULT now(),
+    "last_used_at"  TIMESTAMPTZ,
+
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS room_api_keys_room_id_idx ON room_api_keys (room_id);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS "attribution" VARCHAR(64) NOT NULL DEFAULT '';
+
+---- create above / drop below ----
+
+ALTER TABLE messages DROP COLUMN IF EXISTS "attribution";
+DROP TABLE IF EXISTS room_api_keys;
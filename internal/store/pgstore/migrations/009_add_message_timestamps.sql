@@ -0,0 +1,7 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS "created_at" TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS "answered_at" TIMESTAMPTZ;
+
+---- create above / drop below ----
+
+ALTER TABLE messages DROP COLUMN IF EXISTS "answered_at";
+ALTER TABLE messages DROP COLUMN IF EXISTS "created_at";
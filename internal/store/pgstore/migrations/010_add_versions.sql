@@ -0,0 +1,43 @@
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+
+-- Incrementa a versão de cada linha alterada, a menos que a própria instrução já a tenha alterado.
+CREATE OR REPLACE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    IF NEW.version = OLD.version THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Incrementa a versão da sala sempre que uma das suas mensagens é criada, alterada ou removida.
+CREATE OR REPLACE FUNCTION bump_room_version() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE rooms SET version = version + 1 WHERE id = OLD.room_id;
+    ELSE
+        UPDATE rooms SET version = version + 1 WHERE id = NEW.room_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER rooms_bump_version BEFORE UPDATE ON rooms
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER messages_bump_version BEFORE UPDATE ON messages
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER messages_bump_room_version AFTER INSERT OR UPDATE OR DELETE ON messages
+    FOR EACH ROW EXECUTE FUNCTION bump_room_version();
+
+---- create above / drop below ----
+
+DROP TRIGGER IF EXISTS messages_bump_room_version ON messages;
+DROP TRIGGER IF EXISTS messages_bump_version ON messages;
+DROP TRIGGER IF EXISTS rooms_bump_version ON rooms;
+DROP FUNCTION IF EXISTS bump_room_version();
+DROP FUNCTION IF EXISTS bump_version();
+ALTER TABLE messages DROP COLUMN IF EXISTS "version";
+ALTER TABLE rooms DROP COLUMN IF EXISTS "version";
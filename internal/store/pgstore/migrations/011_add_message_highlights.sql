@@ -0,0 +1,9 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS "pinned" BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS "now_answering_id" uuid
+    REFERENCES messages(id) ON DELETE SET NULL;
+
+---- create above / drop below ----
+
+ALTER TABLE rooms DROP COLUMN IF EXISTS "now_answering_id";
+ALTER TABLE messages DROP COLUMN IF EXISTS "pinned";
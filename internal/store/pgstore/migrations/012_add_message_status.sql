@@ -0,0 +1,17 @@
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS "status" VARCHAR(16) NOT NULL DEFAULT 'open'
+    CHECK ("status" IN ('open', 'answering', 'answered', 'dismissed', 'duplicate'));
+
+UPDATE messages SET "status" = 'answered' WHERE "answered";
+
+-- 'answered' passa a ser derivada de 'status' e é mantida para o formato da API v1
+ALTER TABLE messages DROP COLUMN IF EXISTS "answered";
+ALTER TABLE messages ADD COLUMN "answered" BOOLEAN GENERATED ALWAYS AS ("status" = 'answered') STORED;
+
+---- create above / drop below ----
+
+ALTER TABLE messages DROP COLUMN IF EXISTS "answered";
+ALTER TABLE messages ADD COLUMN "answered" BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE messages SET "answered" = true WHERE "status" = 'answered';
+
+ALTER TABLE messages DROP COLUMN IF EXISTS "status";
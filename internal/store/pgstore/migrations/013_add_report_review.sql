@@ -0,0 +1,5 @@
+ALTER TABLE message_reports ADD COLUMN IF NOT EXISTS "reviewed_at" TIMESTAMPTZ;
+
+---- create above / drop below ----
+
+ALTER TABLE message_reports DROP COLUMN IF EXISTS "reviewed_at";
@@ -0,0 +1,20 @@
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS "updated_at" TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Atualiza o momento da última alteração da sala. Como as alterações das mensagens também alteram a sala, pelo gatilho
+-- messages_bump_room_version, ele cobre a sala e as suas mensagens. Nunca volta no tempo, mesmo que uma transação
+-- iniciada antes confirme depois.
+CREATE OR REPLACE FUNCTION touch_room() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at := GREATEST(OLD.updated_at, clock_timestamp());
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER rooms_touch BEFORE UPDATE ON rooms
+    FOR EACH ROW EXECUTE FUNCTION touch_room();
+
+---- create above / drop below ----
+
+DROP TRIGGER IF EXISTS rooms_touch ON rooms;
+DROP FUNCTION IF EXISTS touch_room();
+ALTER TABLE rooms DROP COLUMN IF EXISTS "updated_at";
@@ -6,17 +6,94 @@ package pgstore
 
 import (
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Message struct {
-	ID            uuid.UUID `db:"id" json:"id"`
-	RoomID        uuid.UUID `db:"room_id" json:"room_id"`
-	Message       string    `db:"message" json:"message"`
-	ReactionCount int64     `db:"reaction_count" json:"reaction_count"`
-	Answered      bool      `db:"answered" json:"answered"`
+	ID               uuid.UUID          `db:"id" json:"id"`
+	RoomID           uuid.UUID          `db:"room_id" json:"room_id"`
+	Message          string             `db:"message" json:"message"`
+	ReactionCount    int64              `db:"reaction_count" json:"reaction_count"`
+	Answered         bool               `db:"answered" json:"answered"`
+	ModerationStatus string             `db:"moderation_status" json:"moderation_status"`
+	Attribution      string             `db:"attribution" json:"attribution"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	AnsweredAt       pgtype.Timestamptz `db:"answered_at" json:"answered_at"`
+	Version          int64              `db:"version" json:"version"`
+	Pinned           bool               `db:"pinned" json:"pinned"`
+	Status           string             `db:"status" json:"status"`
+}
+
+type MessageReport struct {
+	ID         uuid.UUID          `db:"id" json:"id"`
+	MessageID  uuid.UUID          `db:"message_id" json:"message_id"`
+	Reporter   string             `db:"reporter" json:"reporter"`
+	Reason     string             `db:"reason" json:"reason"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ReviewedAt pgtype.Timestamptz `db:"reviewed_at" json:"reviewed_at"`
 }
 
 type Room struct {
-	ID    uuid.UUID `db:"id" json:"id"`
-	Theme string    `db:"theme" json:"theme"`
+	ID             uuid.UUID          `db:"id" json:"id"`
+	Theme          string             `db:"theme" json:"theme"`
+	Moderated      bool               `db:"moderated" json:"moderated"`
+	Version        int64              `db:"version" json:"version"`
+	NowAnsweringID pgtype.UUID        `db:"now_answering_id" json:"now_answering_id"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type RoomApiKey struct {
+	ID         uuid.UUID          `db:"id" json:"id"`
+	RoomID     uuid.UUID          `db:"room_id" json:"room_id"`
+	Name       string             `db:"name" json:"name"`
+	KeyHash    string             `db:"key_hash" json:"key_hash"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	LastUsedAt pgtype.Timestamptz `db:"last_used_at" json:"last_used_at"`
+}
+
+type RoomBan struct {
+	ID            uuid.UUID          `db:"id" json:"id"`
+	RoomID        uuid.UUID          `db:"room_id" json:"room_id"`
+	ParticipantID string             `db:"participant_id" json:"participant_id"`
+	IpRange       string             `db:"ip_range" json:"ip_range"`
+	Kind          string             `db:"kind" json:"kind"`
+	Reason        string             `db:"reason" json:"reason"`
+	ExpiresAt     pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type RoomFilterRule struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	RoomID  uuid.UUID `db:"room_id" json:"room_id"`
+	Pattern string    `db:"pattern" json:"pattern"`
+	IsRegex bool      `db:"is_regex" json:"is_regex"`
+	Action  string    `db:"action" json:"action"`
+}
+
+type RoomModeratorToken struct {
+	RoomID    uuid.UUID `db:"room_id" json:"room_id"`
+	TokenHash string    `db:"token_hash" json:"token_hash"`
+}
+
+type RoomWebhook struct {
+	ID         uuid.UUID          `db:"id" json:"id"`
+	RoomID     uuid.UUID          `db:"room_id" json:"room_id"`
+	Url        string             `db:"url" json:"url"`
+	Secret     string             `db:"secret" json:"secret"`
+	EventKinds []string           `db:"event_kinds" json:"event_kinds"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID          `db:"id" json:"id"`
+	WebhookID      uuid.UUID          `db:"webhook_id" json:"webhook_id"`
+	EventKind      string             `db:"event_kind" json:"event_kind"`
+	Payload        string             `db:"payload" json:"payload"`
+	Status         string             `db:"status" json:"status"`
+	Attempts       int32              `db:"attempts" json:"attempts"`
+	LastStatusCode int32              `db:"last_status_code" json:"last_status_code"`
+	LastError      string             `db:"last_error" json:"last_error"`
+	NextAttemptAt  pgtype.Timestamptz `db:"next_attempt_at" json:"next_attempt_at"`
+	DeliveredAt    pgtype.Timestamptz `db:"delivered_at" json:"delivered_at"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
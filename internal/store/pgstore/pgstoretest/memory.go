@@ -0,0 +1,847 @@
+package pgstoretest
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Memory é uma implementação de pgstore.Querier em memória, para os testes que não precisam do PostgreSQL.
+// As consultas reproduzem as de pgstore, inclusive os gatilhos de versão e as restrições de unicidade e de chave estrangeira.
+//
+// Como reimplementa as consultas, Memory pode divergir do SQL: serve para verificar contratos, como os formatos e os
+// códigos de status das respostas, e a lógica que usa o banco de dados apenas como armazenamento. O comportamento que
+// depende das consultas, dos gatilhos ou dos bloqueios deve ser verificado com New, obrigatório na CI.
+//
+// Memory também satisfaz a interface de banco de dados do pacote api (Ping, QueryRow e Begin), com as migrações
+// já aplicadas. As transações são serializadas, como se cada uma bloqueasse todas as linhas, e o Rollback
+// restaura os dados do início da transação.
+type Memory struct {
+	txMu sync.Mutex // Mantido durante cada transação
+	mu   sync.Mutex // Protege data
+	data memData
+}
+
+// memData são as tabelas do banco de dados, na ordem de inserção das linhas.
+type memData struct {
+	rooms      []pgstore.Room
+	tokens     map[uuid.UUID]string // room_moderator_tokens
+	messages   []pgstore.Message
+	bans       []pgstore.RoomBan
+	filters    []pgstore.RoomFilterRule
+	reports    []pgstore.MessageReport
+	apiKeys    []pgstore.RoomApiKey
+	webhooks   []pgstore.RoomWebhook
+	deliveries []pgstore.WebhookDelivery
+}
+
+func (d memData) clone() memData {
+	return memData{
+		rooms:      slices.Clone(d.rooms),
+		tokens:     maps.Clone(d.tokens),
+		messages:   slices.Clone(d.messages),
+		bans:       slices.Clone(d.bans),
+		filters:    slices.Clone(d.filters),
+		reports:    slices.Clone(d.reports),
+		apiKeys:    slices.Clone(d.apiKeys),
+		webhooks:   slices.Clone(d.webhooks),
+		deliveries: slices.Clone(d.deliveries),
+	}
+}
+
+var _ pgstore.Querier = (*Memory)(nil)
+
+// NewMemory cria um banco de dados em memória vazio.
+func NewMemory() *Memory {
+	return &Memory{data: memData{tokens: make(map[uuid.UUID]string)}}
+}
+
+// Ping sempre tem sucesso.
+func (m *Memory) Ping(ctx context.Context) error {
+	return nil
+}
+
+// QueryRow atende apenas à consulta da versão do esquema feita por /readyz.
+func (m *Memory) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if strings.Contains(sql, "schema_version") {
+		return memRow{values: []any{pgstore.SchemaVersion()}}
+	}
+	return memRow{err: fmt.Errorf("pgstoretest: unsupported query %q", sql)}
+}
+
+// Begin abre uma transação, aguardando o fim da transação anterior.
+func (m *Memory) Begin(ctx context.Context) (pgx.Tx, error) {
+	m.txMu.Lock()
+
+	m.mu.Lock()
+	snapshot := m.data.clone()
+	m.mu.Unlock()
+
+	return &memTx{m: m, snapshot: snapshot}, nil
+}
+
+// WithTx retorna as consultas da transação; como as transações são serializadas, elas alteram os dados diretamente.
+func (m *Memory) WithTx(tx pgx.Tx) pgstore.Querier {
+	return m
+}
+
+// memTx é uma transação de Memory. Os demais métodos de pgx.Tx não são usados e entram em pânico.
+type memTx struct {
+	pgx.Tx
+	m        *Memory
+	snapshot memData
+	done     bool
+}
+
+func (tx *memTx) Commit(ctx context.Context) error {
+	if tx.done {
+		return pgx.ErrTxClosed
+	}
+	tx.done = true
+	tx.m.txMu.Unlock()
+	return nil
+}
+
+func (tx *memTx) Rollback(ctx context.Context) error {
+	if tx.done {
+		return pgx.ErrTxClosed
+	}
+	tx.done = true
+	tx.m.mu.Lock()
+	tx.m.data = tx.snapshot
+	tx.m.mu.Unlock()
+	tx.m.txMu.Unlock()
+	return nil
+}
+
+// memRow é o resultado de QueryRow.
+type memRow struct {
+	values []any
+	err    error
+}
+
+func (r memRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(dest) != len(r.values) {
+		return fmt.Errorf("pgstoretest: scan %d values into %d destinations", len(r.values), len(dest))
+	}
+	for i, v := range r.values {
+		switch d := dest[i].(type) {
+		case *int:
+			*d = v.(int)
+		default:
+			return fmt.Errorf("pgstoretest: unsupported scan destination %T", dest[i])
+		}
+	}
+	return nil
+}
+
+// Erros das restrições do esquema, com os mesmos códigos do PostgreSQL
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint", ConstraintName: constraint}
+}
+
+func foreignKeyViolation(constraint string) error {
+	return &pgconn.PgError{Code: "23503", Message: "insert or update violates foreign key constraint", ConstraintName: constraint}
+}
+
+func now() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now(), Valid: true}
+}
+
+// room retorna o índice da sala, ou -1.
+func (d *memData) room(id uuid.UUID) int {
+	return slices.IndexFunc(d.rooms, func(r pgstore.Room) bool { return r.ID == id })
+}
+
+// message retorna o índice da mensagem, ou -1.
+func (d *memData) message(id uuid.UUID) int {
+	return slices.IndexFunc(d.messages, func(msg pgstore.Message) bool { return msg.ID == id })
+}
+
+// bumpRoom reproduz os gatilhos rooms_bump_version e rooms_touch, disparados também pelas alterações das mensagens da sala.
+func (d *memData) bumpRoom(id uuid.UUID) {
+	if i := d.room(id); i >= 0 {
+		touchRoom(&d.rooms[i])
+	}
+}
+
+// touchRoom incrementa a versão da sala e avança o momento da sua última alteração, como os gatilhos das salas.
+func touchRoom(room *pgstore.Room) {
+	room.Version++
+	if t := time.Now(); t.After(room.UpdatedAt.Time) {
+		room.UpdatedAt = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+}
+
+// updateMessage aplica fn à mensagem e reproduz os gatilhos messages_bump_version e messages_bump_room_version.
+func (d *memData) updateMessage(i int, fn func(msg *pgstore.Message)) pgstore.Message {
+	msg := &d.messages[i]
+	fn(msg)
+	msg.Answered = msg.Status == "answered" // Coluna gerada
+	msg.Version++
+	d.bumpRoom(msg.RoomID)
+	return *msg
+}
+
+// insertMessage insere a mensagem com os valores padrão das colunas omitidas.
+func (d *memData) insertMessage(msg pgstore.Message) (uuid.UUID, error) {
+	if d.room(msg.RoomID) < 0 {
+		return uuid.UUID{}, foreignKeyViolation("messages_room_id_fkey")
+	}
+
+	msg.ID = uuid.New()
+	msg.Status = cmp.Or(msg.Status, "open")
+	msg.Answered = msg.Status == "answered"
+	msg.CreatedAt = now()
+	msg.Version = 1
+	d.messages = append(d.messages, msg)
+	d.bumpRoom(msg.RoomID)
+	return msg.ID, nil
+}
+
+// lock executa fn com os dados bloqueados.
+func (m *Memory) lock(fn func(d *memData)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(&m.data)
+}
+
+// Salas
+
+func (m *Memory) GetRoom(ctx context.Context, id uuid.UUID) (room pgstore.Room, err error) {
+	m.lock(func(d *memData) {
+		i := d.room(id)
+		if i < 0 {
+			err = pgx.ErrNoRows
+			return
+		}
+		room = d.rooms[i]
+	})
+	return room, err
+}
+
+func (m *Memory) GetRooms(ctx context.Context) (rooms []pgstore.Room, err error) {
+	m.lock(func(d *memData) {
+		rooms = slices.Clone(d.rooms)
+	})
+	return rooms, nil
+}
+
+func (m *Memory) InsertRoom(ctx context.Context, arg pgstore.InsertRoomParams) (id uuid.UUID, err error) {
+	m.lock(func(d *memData) {
+		id = uuid.New()
+		d.rooms = append(d.rooms, pgstore.Room{ID: id, Theme: arg.Theme, Moderated: arg.Moderated, Version: 1, UpdatedAt: now()})
+	})
+	return id, nil
+}
+
+func (m *Memory) LockRoomVersion(ctx context.Context, id uuid.UUID) (int64, error) {
+	room, err := m.GetRoom(ctx, id)
+	return room.Version, err
+}
+
+// updateRoom aplica fn à sala e reproduz os gatilhos rooms_bump_version e rooms_touch.
+func (m *Memory) updateRoom(id uuid.UUID, fn func(d *memData, room *pgstore.Room) bool) (room pgstore.Room, err error) {
+	m.lock(func(d *memData) {
+		i := d.room(id)
+		if i < 0 || !fn(d, &d.rooms[i]) {
+			err = pgx.ErrNoRows
+			return
+		}
+		touchRoom(&d.rooms[i])
+		room = d.rooms[i]
+	})
+	return room, err
+}
+
+func (m *Memory) SetRoomModerated(ctx context.Context, arg pgstore.SetRoomModeratedParams) (pgstore.Room, error) {
+	return m.updateRoom(arg.ID, func(d *memData, room *pgstore.Room) bool {
+		room.Moderated = arg.Moderated
+		return true
+	})
+}
+
+func (m *Memory) SetRoomNowAnswering(ctx context.Context, arg pgstore.SetRoomNowAnsweringParams) (pgstore.Room, error) {
+	return m.updateRoom(arg.RoomID, func(d *memData, room *pgstore.Room) bool {
+		i := d.message(arg.MessageID)
+		if i < 0 || d.messages[i].RoomID != room.ID || d.messages[i].ModerationStatus != "approved" {
+			return false
+		}
+		room.NowAnsweringID = pgtype.UUID{Bytes: arg.MessageID, Valid: true}
+		return true
+	})
+}
+
+func (m *Memory) ClearRoomNowAnswering(ctx context.Context, id uuid.UUID) (pgstore.Room, error) {
+	return m.updateRoom(id, func(d *memData, room *pgstore.Room) bool {
+		room.NowAnsweringID = pgtype.UUID{}
+		return true
+	})
+}
+
+func (m *Memory) InsertRoomModeratorToken(ctx context.Context, arg pgstore.InsertRoomModeratorTokenParams) (err error) {
+	m.lock(func(d *memData) {
+		if d.room(arg.RoomID) < 0 {
+			err = foreignKeyViolation("room_moderator_tokens_room_id_fkey")
+			return
+		}
+		if _, ok := d.tokens[arg.RoomID]; ok {
+			err = uniqueViolation("room_moderator_tokens_pkey")
+			return
+		}
+		d.tokens[arg.RoomID] = arg.TokenHash
+	})
+	return err
+}
+
+func (m *Memory) UpsertRoomModeratorToken(ctx context.Context, arg pgstore.UpsertRoomModeratorTokenParams) (err error) {
+	m.lock(func(d *memData) {
+		if d.room(arg.RoomID) < 0 {
+			err = foreignKeyViolation("room_moderator_tokens_room_id_fkey")
+			return
+		}
+		d.tokens[arg.RoomID] = arg.TokenHash
+	})
+	return err
+}
+
+func (m *Memory) GetRoomModeratorTokenHash(ctx context.Context, roomID uuid.UUID) (hash string, err error) {
+	m.lock(func(d *memData) {
+		var ok bool
+		if hash, ok = d.tokens[roomID]; !ok {
+			err = pgx.ErrNoRows
+		}
+	})
+	return hash, err
+}
+
+// Mensagens
+
+func (m *Memory) GetMessage(ctx context.Context, id uuid.UUID) (msg pgstore.Message, err error) {
+	m.lock(func(d *memData) {
+		i := d.message(id)
+		if i < 0 {
+			err = pgx.ErrNoRows
+			return
+		}
+		msg = d.messages[i]
+	})
+	return msg, err
+}
+
+// filterMessages retorna as mensagens que satisfazem keep, na ordem de inserção.
+func (m *Memory) filterMessages(keep func(d *memData, msg pgstore.Message) bool) (messages []pgstore.Message) {
+	m.lock(func(d *memData) {
+		for _, msg := range d.messages {
+			if keep(d, msg) {
+				messages = append(messages, msg)
+			}
+		}
+	})
+	return messages
+}
+
+func (m *Memory) GetRoomMessages(ctx context.Context, roomID uuid.UUID) ([]pgstore.Message, error) {
+	return m.filterMessages(func(d *memData, msg pgstore.Message) bool {
+		return msg.RoomID == roomID
+	}), nil
+}
+
+func (m *Memory) GetRoomMessagesByModerationStatus(ctx context.Context, arg pgstore.GetRoomMessagesByModerationStatusParams) ([]pgstore.Message, error) {
+	return m.filterMessages(func(d *memData, msg pgstore.Message) bool {
+		return msg.RoomID == arg.RoomID && msg.ModerationStatus == arg.ModerationStatus
+	}), nil
+}
+
+func (m *Memory) GetRoomHighlightedMessages(ctx context.Context, roomID uuid.UUID) ([]pgstore.Message, error) {
+	messages := m.filterMessages(func(d *memData, msg pgstore.Message) bool {
+		if msg.RoomID != roomID || msg.ModerationStatus != "approved" {
+			return false
+		}
+		i := d.room(roomID)
+		return msg.Pinned || (i >= 0 && d.rooms[i].NowAnsweringID.Valid && d.rooms[i].NowAnsweringID.Bytes == msg.ID)
+	})
+	slices.SortStableFunc(messages, func(a, b pgstore.Message) int { return a.CreatedAt.Time.Compare(b.CreatedAt.Time) })
+	return messages, nil
+}
+
+func (m *Memory) InsertMessage(ctx context.Context, arg pgstore.InsertMessageParams) (id uuid.UUID, err error) {
+	m.lock(func(d *memData) {
+		id, err = d.insertMessage(pgstore.Message{
+			RoomID:           arg.RoomID,
+			Message:          arg.Message,
+			ModerationStatus: arg.ModerationStatus,
+			Attribution:      arg.Attribution,
+		})
+	})
+	return id, err
+}
+
+func (m *Memory) ImportMessage(ctx context.Context, arg pgstore.ImportMessageParams) (err error) {
+	m.lock(func(d *memData) {
+		_, err = d.insertMessage(pgstore.Message{
+			RoomID:           arg.RoomID,
+			Message:          arg.Message,
+			ReactionCount:    arg.ReactionCount,
+			Status:           arg.Status,
+			ModerationStatus: arg.ModerationStatus,
+			Attribution:      arg.Attribution,
+		})
+	})
+	return err
+}
+
+func (m *Memory) CopyUnansweredMessages(ctx context.Context, arg pgstore.CopyUnansweredMessagesParams) (n int64, err error) {
+	m.lock(func(d *memData) {
+		for _, msg := range slices.Clone(d.messages) {
+			if msg.RoomID != arg.SourceRoomID || (msg.Status != "open" && msg.Status != "answering") || msg.ModerationStatus == "rejected" {
+				continue
+			}
+			if _, err = d.insertMessage(pgstore.Message{
+				RoomID:           arg.TargetRoomID,
+				Message:          msg.Message,
+				ReactionCount:    msg.ReactionCount,
+				ModerationStatus: msg.ModerationStatus,
+				Attribution:      msg.Attribution,
+			}); err != nil {
+				return
+			}
+			n++
+		}
+	})
+	return n, err
+}
+
+// updateMessageWhere altera a mensagem da sala se ela satisfizer cond; caso contrário retorna pgx.ErrNoRows.
+func (m *Memory) updateMessageWhere(id, roomID uuid.UUID, cond func(msg pgstore.Message) bool, fn func(msg *pgstore.Message)) (msg pgstore.Message, err error) {
+	m.lock(func(d *memData) {
+		i := d.message(id)
+		if i < 0 || d.messages[i].RoomID != roomID || !cond(d.messages[i]) {
+			err = pgx.ErrNoRows
+			return
+		}
+		msg = d.updateMessage(i, fn)
+	})
+	return msg, err
+}
+
+func (m *Memory) LockMessageVersion(ctx context.Context, arg pgstore.LockMessageVersionParams) (int64, error) {
+	msg, err := m.GetMessage(ctx, arg.ID)
+	if err == nil && msg.RoomID != arg.RoomID {
+		err = pgx.ErrNoRows
+	}
+	return msg.Version, err
+}
+
+func (m *Memory) UpdatePendingMessageModerationStatus(ctx context.Context, arg pgstore.UpdatePendingMessageModerationStatusParams) (pgstore.Message, error) {
+	return m.updateMessageWhere(arg.ID, arg.RoomID,
+		func(msg pgstore.Message) bool { return msg.ModerationStatus == "pending" },
+		func(msg *pgstore.Message) { msg.ModerationStatus = arg.ModerationStatus },
+	)
+}
+
+func (m *Memory) ReactToMessage(ctx context.Context, arg pgstore.ReactToMessageParams) (int64, error) {
+	msg, err := m.updateMessageWhere(arg.ID, arg.RoomID,
+		func(msg pgstore.Message) bool { return msg.ModerationStatus == "approved" },
+		func(msg *pgstore.Message) { msg.ReactionCount++ },
+	)
+	return msg.ReactionCount, err
+}
+
+func (m *Memory) RemoveReactionFromMessage(ctx context.Context, arg pgstore.RemoveReactionFromMessageParams) (int64, error) {
+	msg, err := m.updateMessageWhere(arg.ID, arg.RoomID,
+		func(msg pgstore.Message) bool { return msg.ModerationStatus == "approved" },
+		func(msg *pgstore.Message) { msg.ReactionCount-- },
+	)
+	return msg.ReactionCount, err
+}
+
+func (m *Memory) UpdateMessageStatus(ctx context.Context, arg pgstore.UpdateMessageStatusParams) (pgstore.Message, error) {
+	return m.updateMessageWhere(arg.ID, arg.RoomID,
+		func(msg pgstore.Message) bool { return msg.Status == arg.OldStatus },
+		func(msg *pgstore.Message) {
+			msg.Status = arg.Status
+			switch {
+			case arg.Status != "answered":
+				msg.AnsweredAt = pgtype.Timestamptz{}
+			case !msg.AnsweredAt.Valid:
+				msg.AnsweredAt = now()
+			}
+		},
+	)
+}
+
+func (m *Memory) SetMessagePinned(ctx context.Context, arg pgstore.SetMessagePinnedParams) (pgstore.Message, error) {
+	return m.updateMessageWhere(arg.ID, arg.RoomID,
+		func(msg pgstore.Message) bool { return msg.ModerationStatus == "approved" || !arg.Pinned },
+		func(msg *pgstore.Message) { msg.Pinned = arg.Pinned },
+	)
+}
+
+func (m *Memory) HideMessage(ctx context.Context, id uuid.UUID) (n int64, err error) {
+	m.lock(func(d *memData) {
+		i := d.message(id)
+		if i < 0 || d.messages[i].ModerationStatus != "approved" {
+			return
+		}
+		d.updateMessage(i, func(msg *pgstore.Message) { msg.ModerationStatus = "pending" })
+		n = 1
+	})
+	return n, nil
+}
+
+// Banimentos
+
+func (m *Memory) GetActiveRoomBans(ctx context.Context, roomID uuid.UUID) (bans []pgstore.RoomBan, err error) {
+	m.lock(func(d *memData) {
+		for _, ban := range d.bans {
+			if ban.RoomID == roomID && (!ban.ExpiresAt.Valid || ban.ExpiresAt.Time.After(time.Now())) {
+				bans = append(bans, ban)
+			}
+		}
+	})
+	return bans, nil
+}
+
+func (m *Memory) InsertRoomBan(ctx context.Context, arg pgstore.InsertRoomBanParams) (ban pgstore.RoomBan, err error) {
+	m.lock(func(d *memData) {
+		if d.room(arg.RoomID) < 0 {
+			err = foreignKeyViolation("room_bans_room_id_fkey")
+			return
+		}
+		ban = pgstore.RoomBan{
+			ID:            uuid.New(),
+			RoomID:        arg.RoomID,
+			ParticipantID: arg.ParticipantID,
+			IpRange:       arg.IpRange,
+			Kind:          arg.Kind,
+			Reason:        arg.Reason,
+			ExpiresAt:     arg.ExpiresAt,
+			CreatedAt:     now(),
+		}
+		d.bans = append(d.bans, ban)
+	})
+	return ban, err
+}
+
+// deleteWhere remove as linhas que satisfazem match e retorna quantas foram removidas.
+func deleteWhere[T any](rows *[]T, match func(T) bool) int64 {
+	before := len(*rows)
+	*rows = slices.DeleteFunc(*rows, match)
+	return int64(before - len(*rows))
+}
+
+func (m *Memory) DeleteRoomBan(ctx context.Context, arg pgstore.DeleteRoomBanParams) (n int64, err error) {
+	m.lock(func(d *memData) {
+		n = deleteWhere(&d.bans, func(ban pgstore.RoomBan) bool { return ban.ID == arg.ID && ban.RoomID == arg.RoomID })
+	})
+	return n, nil
+}
+
+// Filtro de conteúdo
+
+func (m *Memory) GetRoomFilterRules(ctx context.Context, roomID uuid.UUID) (rules []pgstore.RoomFilterRule, err error) {
+	m.lock(func(d *memData) {
+		for _, rule := range d.filters {
+			if rule.RoomID == roomID {
+				rules = append(rules, rule)
+			}
+		}
+	})
+	return rules, nil
+}
+
+func (m *Memory) InsertRoomFilterRule(ctx context.Context, arg pgstore.InsertRoomFilterRuleParams) (rule pgstore.RoomFilterRule, err error) {
+	m.lock(func(d *memData) {
+		if d.room(arg.RoomID) < 0 {
+			err = foreignKeyViolation("room_filter_rules_room_id_fkey")
+			return
+		}
+		rule = pgstore.RoomFilterRule{ID: uuid.New(), RoomID: arg.RoomID, Pattern: arg.Pattern, IsRegex: arg.IsRegex, Action: arg.Action}
+		d.filters = append(d.filters, rule)
+	})
+	return rule, err
+}
+
+func (m *Memory) CopyRoomFilterRules(ctx context.Context, arg pgstore.CopyRoomFilterRulesParams) (err error) {
+	rules, _ := m.GetRoomFilterRules(ctx, arg.SourceRoomID)
+	for _, rule := range rules {
+		if _, err := m.InsertRoomFilterRule(ctx, pgstore.InsertRoomFilterRuleParams{
+			RoomID:  arg.TargetRoomID,
+			Pattern: rule.Pattern,
+			IsRegex: rule.IsRegex,
+			Action:  rule.Action,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) DeleteRoomFilterRule(ctx context.Context, arg pgstore.DeleteRoomFilterRuleParams) (n int64, err error) {
+	m.lock(func(d *memData) {
+		n = deleteWhere(&d.filters, func(rule pgstore.RoomFilterRule) bool { return rule.ID == arg.ID && rule.RoomID == arg.RoomID })
+	})
+	return n, nil
+}
+
+// Denúncias
+
+func (m *Memory) InsertMessageReport(ctx context.Context, arg pgstore.InsertMessageReportParams) (n int64, err error) {
+	m.lock(func(d *memData) {
+		if d.message(arg.MessageID) < 0 {
+			err = foreignKeyViolation("message_reports_message_id_fkey")
+			return
+		}
+		if slices.ContainsFunc(d.reports, func(r pgstore.MessageReport) bool { return r.MessageID == arg.MessageID && r.Reporter == arg.Reporter }) {
+			return // ON CONFLICT DO NOTHING
+		}
+		d.reports = append(d.reports, pgstore.MessageReport{ID: uuid.New(), MessageID: arg.MessageID, Reporter: arg.Reporter, Reason: arg.Reason, CreatedAt: now()})
+		n = 1
+	})
+	return n, err
+}
+
+func (m *Memory) CountMessageReports(ctx context.Context, messageID uuid.UUID) (n int64, err error) {
+	m.lock(func(d *memData) {
+		for _, r := range d.reports {
+			if r.MessageID == messageID && !r.ReviewedAt.Valid {
+				n++
+			}
+		}
+	})
+	return n, nil
+}
+
+func (m *Memory) MarkMessageReportsReviewed(ctx context.Context, messageID uuid.UUID) error {
+	m.lock(func(d *memData) {
+		for i, r := range d.reports {
+			if r.MessageID == messageID && !r.ReviewedAt.Valid {
+				d.reports[i].ReviewedAt = now()
+			}
+		}
+	})
+	return nil
+}
+
+func (m *Memory) GetRoomReportedMessages(ctx context.Context, roomID uuid.UUID) (rows []pgstore.GetRoomReportedMessagesRow, err error) {
+	m.lock(func(d *memData) {
+		for _, msg := range d.messages {
+			if msg.RoomID != roomID {
+				continue
+			}
+			var count int64
+			for _, r := range d.reports {
+				if r.MessageID == msg.ID && !r.ReviewedAt.Valid {
+					count++
+				}
+			}
+			if count > 0 {
+				rows = append(rows, pgstore.GetRoomReportedMessagesRow{ID: msg.ID, Message: msg.Message, ModerationStatus: msg.ModerationStatus, ReportCount: count})
+			}
+		}
+	})
+	slices.SortStableFunc(rows, func(a, b pgstore.GetRoomReportedMessagesRow) int { return cmp.Compare(b.ReportCount, a.ReportCount) })
+	return rows, nil
+}
+
+// Chaves de API
+
+func apiKeyRow(key pgstore.RoomApiKey) pgstore.GetRoomApiKeysRow {
+	return pgstore.GetRoomApiKeysRow{ID: key.ID, RoomID: key.RoomID, Name: key.Name, CreatedAt: key.CreatedAt, LastUsedAt: key.LastUsedAt}
+}
+
+func (m *Memory) GetRoomApiKeys(ctx context.Context, roomID uuid.UUID) (keys []pgstore.GetRoomApiKeysRow, err error) {
+	m.lock(func(d *memData) {
+		for _, key := range d.apiKeys {
+			if key.RoomID == roomID {
+				keys = append(keys, apiKeyRow(key))
+			}
+		}
+	})
+	return keys, nil
+}
+
+func (m *Memory) GetRoomApiKeyByHash(ctx context.Context, arg pgstore.GetRoomApiKeyByHashParams) (row pgstore.GetRoomApiKeyByHashRow, err error) {
+	m.lock(func(d *memData) {
+		i := slices.IndexFunc(d.apiKeys, func(key pgstore.RoomApiKey) bool { return key.RoomID == arg.RoomID && key.KeyHash == arg.KeyHash })
+		if i < 0 {
+			err = pgx.ErrNoRows
+			return
+		}
+		row = pgstore.GetRoomApiKeyByHashRow(apiKeyRow(d.apiKeys[i]))
+	})
+	return row, err
+}
+
+func (m *Memory) InsertRoomApiKey(ctx context.Context, arg pgstore.InsertRoomApiKeyParams) (row pgstore.InsertRoomApiKeyRow, err error) {
+	m.lock(func(d *memData) {
+		if d.room(arg.RoomID) < 0 {
+			err = foreignKeyViolation("room_api_keys_room_id_fkey")
+			return
+		}
+		if slices.ContainsFunc(d.apiKeys, func(key pgstore.RoomApiKey) bool { return key.KeyHash == arg.KeyHash }) {
+			err = uniqueViolation("room_api_keys_key_hash_key")
+			return
+		}
+		key := pgstore.RoomApiKey{ID: uuid.New(), RoomID: arg.RoomID, Name: arg.Name, KeyHash: arg.KeyHash, CreatedAt: now()}
+		d.apiKeys = append(d.apiKeys, key)
+		row = pgstore.InsertRoomApiKeyRow(apiKeyRow(key))
+	})
+	return row, err
+}
+
+func (m *Memory) TouchRoomApiKey(ctx context.Context, id uuid.UUID) error {
+	m.lock(func(d *memData) {
+		for i := range d.apiKeys {
+			if d.apiKeys[i].ID == id {
+				d.apiKeys[i].LastUsedAt = now()
+			}
+		}
+	})
+	return nil
+}
+
+func (m *Memory) DeleteRoomApiKey(ctx context.Context, arg pgstore.DeleteRoomApiKeyParams) (n int64, err error) {
+	m.lock(func(d *memData) {
+		n = deleteWhere(&d.apiKeys, func(key pgstore.RoomApiKey) bool { return key.ID == arg.ID && key.RoomID == arg.RoomID })
+	})
+	return n, nil
+}
+
+// Webhooks
+
+func (m *Memory) GetRoomWebhooks(ctx context.Context, roomID uuid.UUID) (rows []pgstore.GetRoomWebhooksRow, err error) {
+	m.lock(func(d *memData) {
+		for _, w := range d.webhooks {
+			if w.RoomID == roomID {
+				rows = append(rows, pgstore.GetRoomWebhooksRow{ID: w.ID, RoomID: w.RoomID, Url: w.Url, EventKinds: w.EventKinds, CreatedAt: w.CreatedAt})
+			}
+		}
+	})
+	return rows, nil
+}
+
+func (m *Memory) InsertRoomWebhook(ctx context.Context, arg pgstore.InsertRoomWebhookParams) (webhook pgstore.RoomWebhook, err error) {
+	m.lock(func(d *memData) {
+		if d.room(arg.RoomID) < 0 {
+			err = foreignKeyViolation("room_webhooks_room_id_fkey")
+			return
+		}
+		webhook = pgstore.RoomWebhook{ID: uuid.New(), RoomID: arg.RoomID, Url: arg.Url, Secret: arg.Secret, EventKinds: slices.Clone(arg.EventKinds), CreatedAt: now()}
+		d.webhooks = append(d.webhooks, webhook)
+	})
+	return webhook, err
+}
+
+func (m *Memory) DeleteRoomWebhook(ctx context.Context, arg pgstore.DeleteRoomWebhookParams) (n int64, err error) {
+	m.lock(func(d *memData) {
+		n = deleteWhere(&d.webhooks, func(w pgstore.RoomWebhook) bool { return w.ID == arg.ID && w.RoomID == arg.RoomID })
+		if n > 0 {
+			deleteWhere(&d.deliveries, func(delivery pgstore.WebhookDelivery) bool { return delivery.WebhookID == arg.ID }) // ON DELETE CASCADE
+		}
+	})
+	return n, nil
+}
+
+func (m *Memory) InsertWebhookDeliveries(ctx context.Context, arg pgstore.InsertWebhookDeliveriesParams) (n int64, err error) {
+	m.lock(func(d *memData) {
+		for _, w := range d.webhooks {
+			if w.RoomID != arg.RoomID || (len(w.EventKinds) > 0 && !slices.Contains(w.EventKinds, arg.EventKind)) {
+				continue
+			}
+			d.deliveries = append(d.deliveries, pgstore.WebhookDelivery{
+				ID:            uuid.New(),
+				WebhookID:     w.ID,
+				EventKind:     arg.EventKind,
+				Payload:       arg.Payload,
+				Status:        "pending",
+				NextAttemptAt: now(),
+				CreatedAt:     now(),
+			})
+			n++
+		}
+	})
+	return n, nil
+}
+
+func (m *Memory) ClaimWebhookDeliveries(ctx context.Context, arg pgstore.ClaimWebhookDeliveriesParams) (rows []pgstore.ClaimWebhookDeliveriesRow, err error) {
+	m.lock(func(d *memData) {
+		var due []int
+		for i, delivery := range d.deliveries {
+			if delivery.Status == "pending" && !delivery.NextAttemptAt.Time.After(time.Now()) {
+				due = append(due, i)
+			}
+		}
+		slices.SortStableFunc(due, func(a, b int) int {
+			return d.deliveries[a].NextAttemptAt.Time.Compare(d.deliveries[b].NextAttemptAt.Time)
+		})
+
+		lease := time.Duration(arg.LeaseSeconds * float64(time.Second))
+		for _, i := range due[:min(len(due), int(arg.BatchSize))] {
+			delivery := &d.deliveries[i]
+			delivery.NextAttemptAt = pgtype.Timestamptz{Time: time.Now().Add(lease), Valid: true}
+
+			j := slices.IndexFunc(d.webhooks, func(w pgstore.RoomWebhook) bool { return w.ID == delivery.WebhookID })
+			if j < 0 {
+				continue
+			}
+			rows = append(rows, pgstore.ClaimWebhookDeliveriesRow{
+				ID:        delivery.ID,
+				WebhookID: delivery.WebhookID,
+				EventKind: delivery.EventKind,
+				Payload:   delivery.Payload,
+				Attempts:  delivery.Attempts,
+				Url:       d.webhooks[j].Url,
+				Secret:    d.webhooks[j].Secret,
+			})
+		}
+	})
+	return rows, nil
+}
+
+func (m *Memory) RecordWebhookDeliveryAttempt(ctx context.Context, arg pgstore.RecordWebhookDeliveryAttemptParams) error {
+	m.lock(func(d *memData) {
+		for i := range d.deliveries {
+			delivery := &d.deliveries[i]
+			if delivery.ID != arg.ID {
+				continue
+			}
+			delivery.Attempts++
+			delivery.Status = arg.Status
+			delivery.LastStatusCode = arg.LastStatusCode
+			delivery.LastError = arg.LastError
+			delivery.NextAttemptAt = arg.NextAttemptAt
+			if arg.Status == "delivered" {
+				delivery.DeliveredAt = now()
+			}
+		}
+	})
+	return nil
+}
+
+func (m *Memory) GetRoomWebhookDeliveries(ctx context.Context, arg pgstore.GetRoomWebhookDeliveriesParams) (deliveries []pgstore.WebhookDelivery, err error) {
+	m.lock(func(d *memData) {
+		for _, delivery := range d.deliveries {
+			if slices.ContainsFunc(d.webhooks, func(w pgstore.RoomWebhook) bool { return w.ID == delivery.WebhookID && w.RoomID == arg.RoomID }) {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+	})
+	slices.SortStableFunc(deliveries, func(a, b pgstore.WebhookDelivery) int { return b.CreatedAt.Time.Compare(a.CreatedAt.Time) })
+	return deliveries[:min(len(deliveries), int(arg.Limit))], nil
+}
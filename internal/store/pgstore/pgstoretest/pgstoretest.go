@@ -0,0 +1,96 @@
+// Package pgstoretest cria bancos de dados descartáveis para os testes: esquemas temporários no PostgreSQL
+// e uma implementação de pgstore.Querier em memória.
+package pgstoretest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// EnvDatabaseURL é a variável de ambiente com a URL do PostgreSQL usado pelos testes.
+// Sem ela, os testes que precisam do banco de dados são ignorados, exceto na CI (veja EnvCI), em que falham.
+const EnvDatabaseURL = "WSRS_TEST_DATABASE_URL"
+
+// EnvCI é a variável de ambiente definida pelos serviços de CI. Os testes de comportamento das consultas rodam
+// apenas no PostgreSQL, então na CI a falta de EnvDatabaseURL é um erro, e não um motivo para ignorá-los.
+const EnvCI = "CI"
+
+// New cria um esquema vazio no banco de dados de EnvDatabaseURL, aplica nele as migrações e retorna uma pool
+// cujas conexões usam esse esquema. O esquema é removido ao fim do teste.
+func New(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv(EnvDatabaseURL)
+	if url == "" {
+		if os.Getenv(EnvCI) != "" {
+			t.Fatal(EnvDatabaseURL + " is required in CI")
+		}
+		t.Skip(EnvDatabaseURL + " is not set")
+	}
+
+	ctx := context.Background()
+	schema := "wsrs_test_" + uuid.NewString()[:8]
+
+	admin, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	defer admin.Close(ctx)
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create test schema: %v", err)
+	}
+	t.Cleanup(func() {
+		conn, err := pgx.Connect(context.Background(), url)
+		if err != nil {
+			t.Logf("drop test schema %s: %v", schema, err)
+			return
+		}
+		defer conn.Close(context.Background())
+		if _, err := conn.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Logf("drop test schema %s: %v", schema, err)
+		}
+	})
+
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatalf("parse %s: %v", EnvDatabaseURL, err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("open test pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := migrate(ctx, pool); err != nil {
+		t.Fatalf("migrate test schema: %v", err)
+	}
+	return pool
+}
+
+// migrate aplica as migrações e registra a versão do esquema como o tern faria, para que /readyz fique pronto.
+func migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	steps, err := pgstore.Migrations()
+	if err != nil {
+		return err
+	}
+	for i, step := range steps {
+		if _, err := pool.Exec(ctx, step); err != nil {
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+	}
+
+	if _, err := pool.Exec(ctx, "CREATE TABLE schema_version (version int4 NOT NULL)"); err != nil {
+		return err
+	}
+	_, err = pool.Exec(ctx, "INSERT INTO schema_version (version) VALUES ($1)", pgstore.SchemaVersion())
+	return err
+}
@@ -11,9 +11,32 @@ import (
 	"github.com/google/uuid"
 )
 
+const clearRoomNowAnswering = `-- name: ClearRoomNowAnswering :one
+UPDATE rooms
+SET
+    now_answering_id = NULL
+WHERE
+    id = $1
+RETURNING "id", "theme", "moderated", "version", "now_answering_id", "updated_at"
+`
+
+func (q *Queries) ClearRoomNowAnswering(ctx context.Context, id uuid.UUID) (Room, error) {
+	row := q.db.QueryRow(ctx, clearRoomNowAnswering, id)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Theme,
+		&i.Moderated,
+		&i.Version,
+		&i.NowAnsweringID,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getMessage = `-- name: GetMessage :one
 SELECT
-    "id", "room_id", "message", "reaction_count", "answered"
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
 FROM messages
 WHERE
     id = $1
@@ -28,13 +51,20 @@ func (q *Queries) GetMessage(ctx context.Context, id uuid.UUID) (Message, error)
 		&i.Message,
 		&i.ReactionCount,
 		&i.Answered,
+		&i.ModerationStatus,
+		&i.Attribution,
+		&i.CreatedAt,
+		&i.AnsweredAt,
+		&i.Version,
+		&i.Pinned,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getRoom = `-- name: GetRoom :one
 SELECT
-    "id", "theme"
+    "id", "theme", "moderated", "version", "now_answering_id", "updated_at"
 FROM rooms
 WHERE id = $1
 `
@@ -42,13 +72,63 @@ WHERE id = $1
 func (q *Queries) GetRoom(ctx context.Context, id uuid.UUID) (Room, error) {
 	row := q.db.QueryRow(ctx, getRoom, id)
 	var i Room
-	err := row.Scan(&i.ID, &i.Theme)
+	err := row.Scan(
+		&i.ID,
+		&i.Theme,
+		&i.Moderated,
+		&i.Version,
+		&i.NowAnsweringID,
+		&i.UpdatedAt,
+	)
 	return i, err
 }
 
+const getRoomHighlightedMessages = `-- name: GetRoomHighlightedMessages :many
+SELECT
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
+FROM messages
+WHERE
+    room_id = $1 AND moderation_status = 'approved'
+    AND (pinned OR id = (SELECT now_answering_id FROM rooms WHERE rooms.id = $1))
+ORDER BY created_at
+`
+
+func (q *Queries) GetRoomHighlightedMessages(ctx context.Context, roomID uuid.UUID) ([]Message, error) {
+	rows, err := q.db.Query(ctx, getRoomHighlightedMessages, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.ReactionCount,
+			&i.Answered,
+			&i.ModerationStatus,
+			&i.Attribution,
+			&i.CreatedAt,
+			&i.AnsweredAt,
+			&i.Version,
+			&i.Pinned,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRoomMessages = `-- name: GetRoomMessages :many
 SELECT
-    "id", "room_id", "message", "reaction_count", "answered"
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
 FROM messages
 WHERE
     room_id = $1
@@ -69,6 +149,13 @@ func (q *Queries) GetRoomMessages(ctx context.Context, roomID uuid.UUID) ([]Mess
 			&i.Message,
 			&i.ReactionCount,
 			&i.Answered,
+			&i.ModerationStatus,
+			&i.Attribution,
+			&i.CreatedAt,
+			&i.AnsweredAt,
+			&i.Version,
+			&i.Pinned,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -80,9 +167,70 @@ func (q *Queries) GetRoomMessages(ctx context.Context, roomID uuid.UUID) ([]Mess
 	return items, nil
 }
 
+const getRoomMessagesByModerationStatus = `-- name: GetRoomMessagesByModerationStatus :many
+SELECT
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
+FROM messages
+WHERE
+    room_id = $1 AND moderation_status = $2
+`
+
+type GetRoomMessagesByModerationStatusParams struct {
+	RoomID           uuid.UUID `db:"room_id" json:"room_id"`
+	ModerationStatus string    `db:"moderation_status" json:"moderation_status"`
+}
+
+func (q *Queries) GetRoomMessagesByModerationStatus(ctx context.Context, arg GetRoomMessagesByModerationStatusParams) ([]Message, error) {
+	rows, err := q.db.Query(ctx, getRoomMessagesByModerationStatus, arg.RoomID, arg.ModerationStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Message
+	for rows.Next() {
+		var i Message
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Message,
+			&i.ReactionCount,
+			&i.Answered,
+			&i.ModerationStatus,
+			&i.Attribution,
+			&i.CreatedAt,
+			&i.AnsweredAt,
+			&i.Version,
+			&i.Pinned,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomModeratorTokenHash = `-- name: GetRoomModeratorTokenHash :one
+SELECT
+    "token_hash"
+FROM room_moderator_tokens
+WHERE
+    room_id = $1
+`
+
+func (q *Queries) GetRoomModeratorTokenHash(ctx context.Context, roomID uuid.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getRoomModeratorTokenHash, roomID)
+	var token_hash string
+	err := row.Scan(&token_hash)
+	return token_hash, err
+}
+
 const getRooms = `-- name: GetRooms :many
 SELECT
-    "id", "theme"
+    "id", "theme", "moderated", "version", "now_answering_id", "updated_at"
 FROM rooms
 `
 
@@ -95,7 +243,14 @@ func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
 	var items []Room
 	for rows.Next() {
 		var i Room
-		if err := rows.Scan(&i.ID, &i.Theme); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Theme,
+			&i.Moderated,
+			&i.Version,
+			&i.NowAnsweringID,
+			&i.UpdatedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -108,18 +263,25 @@ func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
 
 const insertMessage = `-- name: InsertMessage :one
 INSERT INTO messages
-    ( "room_id", "message" ) VALUES
-    ( $1, $2 )
+    ( "room_id", "message", "moderation_status", "attribution" ) VALUES
+    ( $1, $2, $3, $4 )
 RETURNING "id"
 `
 
 type InsertMessageParams struct {
-	RoomID  uuid.UUID `db:"room_id" json:"room_id"`
-	Message string    `db:"message" json:"message"`
+	RoomID           uuid.UUID `db:"room_id" json:"room_id"`
+	Message          string    `db:"message" json:"message"`
+	ModerationStatus string    `db:"moderation_status" json:"moderation_status"`
+	Attribution      string    `db:"attribution" json:"attribution"`
 }
 
 func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, insertMessage, arg.RoomID, arg.Message)
+	row := q.db.QueryRow(ctx, insertMessage,
+		arg.RoomID,
+		arg.Message,
+		arg.ModerationStatus,
+		arg.Attribution,
+	)
 	var id uuid.UUID
 	err := row.Scan(&id)
 	return id, err
@@ -127,28 +289,36 @@ func (q *Queries) InsertMessage(ctx context.Context, arg InsertMessageParams) (u
 
 const insertRoom = `-- name: InsertRoom :one
 INSERT INTO rooms
-    ( "theme" ) VALUES
-    ( $1 )
+    ( "theme", "moderated" ) VALUES
+    ( $1, $2 )
 RETURNING "id"
 `
 
-func (q *Queries) InsertRoom(ctx context.Context, theme string) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, insertRoom, theme)
+type InsertRoomParams struct {
+	Theme     string `db:"theme" json:"theme"`
+	Moderated bool   `db:"moderated" json:"moderated"`
+}
+
+func (q *Queries) InsertRoom(ctx context.Context, arg InsertRoomParams) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, insertRoom, arg.Theme, arg.Moderated)
 	var id uuid.UUID
 	err := row.Scan(&id)
 	return id, err
 }
 
-const markMessageAsAnswered = `-- name: MarkMessageAsAnswered :exec
-UPDATE messages
-SET
-    answered = true
-WHERE
-    id = $1
+const insertRoomModeratorToken = `-- name: InsertRoomModeratorToken :exec
+INSERT INTO room_moderator_tokens
+    ( "room_id", "token_hash" ) VALUES
+    ( $1, $2 )
 `
 
-func (q *Queries) MarkMessageAsAnswered(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, markMessageAsAnswered, id)
+type InsertRoomModeratorTokenParams struct {
+	RoomID    uuid.UUID `db:"room_id" json:"room_id"`
+	TokenHash string    `db:"token_hash" json:"token_hash"`
+}
+
+func (q *Queries) InsertRoomModeratorToken(ctx context.Context, arg InsertRoomModeratorTokenParams) error {
+	_, err := q.db.Exec(ctx, insertRoomModeratorToken, arg.RoomID, arg.TokenHash)
 	return err
 }
 
@@ -157,12 +327,17 @@ UPDATE messages
 SET
     reaction_count = reaction_count + 1
 WHERE
-    id = $1
+    id = $1 AND room_id = $2 AND moderation_status = 'approved'
 RETURNING reaction_count
 `
 
-func (q *Queries) ReactToMessage(ctx context.Context, id uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, reactToMessage, id)
+type ReactToMessageParams struct {
+	ID     uuid.UUID `db:"id" json:"id"`
+	RoomID uuid.UUID `db:"room_id" json:"room_id"`
+}
+
+func (q *Queries) ReactToMessage(ctx context.Context, arg ReactToMessageParams) (int64, error) {
+	row := q.db.QueryRow(ctx, reactToMessage, arg.ID, arg.RoomID)
 	var reaction_count int64
 	err := row.Scan(&reaction_count)
 	return reaction_count, err
@@ -173,13 +348,204 @@ UPDATE messages
 SET
     reaction_count = reaction_count - 1
 WHERE
-    id = $1
+    id = $1 AND room_id = $2 AND moderation_status = 'approved'
 RETURNING reaction_count
 `
 
-func (q *Queries) RemoveReactionFromMessage(ctx context.Context, id uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, removeReactionFromMessage, id)
+type RemoveReactionFromMessageParams struct {
+	ID     uuid.UUID `db:"id" json:"id"`
+	RoomID uuid.UUID `db:"room_id" json:"room_id"`
+}
+
+func (q *Queries) RemoveReactionFromMessage(ctx context.Context, arg RemoveReactionFromMessageParams) (int64, error) {
+	row := q.db.QueryRow(ctx, removeReactionFromMessage, arg.ID, arg.RoomID)
 	var reaction_count int64
 	err := row.Scan(&reaction_count)
 	return reaction_count, err
 }
+
+const setMessagePinned = `-- name: SetMessagePinned :one
+UPDATE messages
+SET
+    pinned = $1::boolean
+WHERE
+    id = $2 AND room_id = $3 AND (moderation_status = 'approved' OR NOT $1::boolean)
+RETURNING "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
+`
+
+type SetMessagePinnedParams struct {
+	Pinned bool      `db:"pinned" json:"pinned"`
+	ID     uuid.UUID `db:"id" json:"id"`
+	RoomID uuid.UUID `db:"room_id" json:"room_id"`
+}
+
+func (q *Queries) SetMessagePinned(ctx context.Context, arg SetMessagePinnedParams) (Message, error) {
+	row := q.db.QueryRow(ctx, setMessagePinned, arg.Pinned, arg.ID, arg.RoomID)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.ReactionCount,
+		&i.Answered,
+		&i.ModerationStatus,
+		&i.Attribution,
+		&i.CreatedAt,
+		&i.AnsweredAt,
+		&i.Version,
+		&i.Pinned,
+		&i.Status,
+	)
+	return i, err
+}
+
+const setRoomModerated = `-- name: SetRoomModerated :one
+UPDATE rooms
+SET
+    moderated = $2
+WHERE
+    id = $1
+RETURNING "id", "theme", "moderated", "version", "now_answering_id", "updated_at"
+`
+
+type SetRoomModeratedParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Moderated bool      `db:"moderated" json:"moderated"`
+}
+
+func (q *Queries) SetRoomModerated(ctx context.Context, arg SetRoomModeratedParams) (Room, error) {
+	row := q.db.QueryRow(ctx, setRoomModerated, arg.ID, arg.Moderated)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Theme,
+		&i.Moderated,
+		&i.Version,
+		&i.NowAnsweringID,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setRoomNowAnswering = `-- name: SetRoomNowAnswering :one
+UPDATE rooms
+SET
+    now_answering_id = m.id
+FROM messages m
+WHERE
+    rooms.id = $1 AND m.id = $2 AND m.room_id = rooms.id AND m.moderation_status = 'approved'
+RETURNING rooms."id", rooms."theme", rooms."moderated", rooms."version", rooms."now_answering_id", rooms."updated_at"
+`
+
+type SetRoomNowAnsweringParams struct {
+	RoomID    uuid.UUID `db:"room_id" json:"room_id"`
+	MessageID uuid.UUID `db:"message_id" json:"message_id"`
+}
+
+func (q *Queries) SetRoomNowAnswering(ctx context.Context, arg SetRoomNowAnsweringParams) (Room, error) {
+	row := q.db.QueryRow(ctx, setRoomNowAnswering, arg.RoomID, arg.MessageID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Theme,
+		&i.Moderated,
+		&i.Version,
+		&i.NowAnsweringID,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateMessageStatus = `-- name: UpdateMessageStatus :one
+UPDATE messages
+SET
+    status = $1,
+    answered_at = CASE WHEN $1 = 'answered' THEN COALESCE(answered_at, now()) END
+WHERE
+    id = $2 AND room_id = $3 AND status = $4
+RETURNING "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
+`
+
+type UpdateMessageStatusParams struct {
+	Status    string    `db:"status" json:"status"`
+	ID        uuid.UUID `db:"id" json:"id"`
+	RoomID    uuid.UUID `db:"room_id" json:"room_id"`
+	OldStatus string    `db:"old_status" json:"old_status"`
+}
+
+func (q *Queries) UpdateMessageStatus(ctx context.Context, arg UpdateMessageStatusParams) (Message, error) {
+	row := q.db.QueryRow(ctx, updateMessageStatus,
+		arg.Status,
+		arg.ID,
+		arg.RoomID,
+		arg.OldStatus,
+	)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.ReactionCount,
+		&i.Answered,
+		&i.ModerationStatus,
+		&i.Attribution,
+		&i.CreatedAt,
+		&i.AnsweredAt,
+		&i.Version,
+		&i.Pinned,
+		&i.Status,
+	)
+	return i, err
+}
+
+const updatePendingMessageModerationStatus = `-- name: UpdatePendingMessageModerationStatus :one
+UPDATE messages
+SET
+    moderation_status = $3
+WHERE
+    id = $1 AND room_id = $2 AND moderation_status = 'pending'
+RETURNING "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
+`
+
+type UpdatePendingMessageModerationStatusParams struct {
+	ID               uuid.UUID `db:"id" json:"id"`
+	RoomID           uuid.UUID `db:"room_id" json:"room_id"`
+	ModerationStatus string    `db:"moderation_status" json:"moderation_status"`
+}
+
+func (q *Queries) UpdatePendingMessageModerationStatus(ctx context.Context, arg UpdatePendingMessageModerationStatusParams) (Message, error) {
+	row := q.db.QueryRow(ctx, updatePendingMessageModerationStatus, arg.ID, arg.RoomID, arg.ModerationStatus)
+	var i Message
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Message,
+		&i.ReactionCount,
+		&i.Answered,
+		&i.ModerationStatus,
+		&i.Attribution,
+		&i.CreatedAt,
+		&i.AnsweredAt,
+		&i.Version,
+		&i.Pinned,
+		&i.Status,
+	)
+	return i, err
+}
+
+const upsertRoomModeratorToken = `-- name: UpsertRoomModeratorToken :exec
+INSERT INTO room_moderator_tokens
+    ( "room_id", "token_hash" ) VALUES
+    ( $1, $2 )
+ON CONFLICT ("room_id") DO UPDATE SET token_hash = EXCLUDED.token_hash
+`
+
+type UpsertRoomModeratorTokenParams struct {
+	RoomID    uuid.UUID `db:"room_id" json:"room_id"`
+	TokenHash string    `db:"token_hash" json:"token_hash"`
+}
+
+func (q *Queries) UpsertRoomModeratorToken(ctx context.Context, arg UpsertRoomModeratorTokenParams) error {
+	_, err := q.db.Exec(ctx, upsertRoomModeratorToken, arg.RoomID, arg.TokenHash)
+	return err
+}
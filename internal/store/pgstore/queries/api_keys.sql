@@ -0,0 +1,49 @@
+-- name: GetRoomApiKeys :many
+SELECT
+    "id", "room_id", "name", "created_at", "last_used_at"
+FROM room_api_keys
+WHERE
+    room_id = $1;
+
+-- Explicação:
+-- Esta consulta retorna as chaves de API de uma sala ($1), sem os seus hashes.
+
+-- name: GetRoomApiKeyByHash :one
+SELECT
+    "id", "room_id", "name", "created_at", "last_used_at"
+FROM room_api_keys
+WHERE
+    room_id = $1 AND key_hash = $2;
+
+-- Explicação:
+-- Esta consulta busca a chave de API de uma sala ($1) a partir do hash SHA-256 da chave apresentada ($2).
+-- Nenhuma linha é retornada se a chave não existir ou pertencer a outra sala.
+
+-- name: InsertRoomApiKey :one
+INSERT INTO room_api_keys
+    ( "room_id", "name", "key_hash" ) VALUES
+    ( $1, $2, $3 )
+RETURNING "id", "room_id", "name", "created_at", "last_used_at";
+
+-- Explicação:
+-- Esta instrução cadastra uma chave de API em uma sala. A chave em si nunca é armazenada, apenas o seu hash ($3).
+-- 'name' identifica o bot ou a origem e é usado como atribuição padrão das mensagens enviadas com a chave.
+
+-- name: DeleteRoomApiKey :execrows
+DELETE FROM room_api_keys
+WHERE
+    id = $1 AND room_id = $2;
+
+-- Explicação:
+-- Esta instrução revoga uma chave de API, identificada pelo 'id' ($1) e pela sala ($2).
+-- Retorna o número de linhas removidas para que seja possível detectar chaves inexistentes.
+
+-- name: TouchRoomApiKey :exec
+UPDATE room_api_keys
+SET
+    last_used_at = now()
+WHERE
+    id = $1;
+
+-- Explicação:
+-- Esta instrução registra o momento do último uso de uma chave de API.
@@ -0,0 +1,29 @@
+-- name: GetActiveRoomBans :many
+SELECT
+    "id", "room_id", "participant_id", "ip_range", "kind", "reason", "expires_at", "created_at"
+FROM room_bans
+WHERE
+    room_id = $1 AND (expires_at IS NULL OR expires_at > now());
+
+-- Explicação:
+-- Esta consulta retorna os banimentos e silenciamentos ainda em vigor em uma sala ($1).
+-- Restrições sem 'expires_at' são permanentes até serem removidas por um moderador.
+
+-- name: InsertRoomBan :one
+INSERT INTO room_bans
+    ( "room_id", "participant_id", "ip_range", "kind", "reason", "expires_at" ) VALUES
+    ( $1, $2, $3, $4, $5, $6 )
+RETURNING "id", "room_id", "participant_id", "ip_range", "kind", "reason", "expires_at", "created_at";
+
+-- Explicação:
+-- Esta instrução registra um banimento ('ban') ou silenciamento ('mute') de um participante e/ou faixa de IPs em uma sala.
+-- Após a inserção, retorna a restrição completa.
+
+-- name: DeleteRoomBan :execrows
+DELETE FROM room_bans
+WHERE
+    id = $1 AND room_id = $2;
+
+-- Explicação:
+-- Esta instrução remove uma restrição, identificada pelo 'id' ($1) e pela sala ($2).
+-- Retorna o número de linhas removidas para que seja possível detectar restrições inexistentes.
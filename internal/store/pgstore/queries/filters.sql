@@ -0,0 +1,29 @@
+-- name: GetRoomFilterRules :many
+SELECT
+    "id", "room_id", "pattern", "is_regex", "action"
+FROM room_filter_rules
+WHERE
+    room_id = $1;
+
+-- Explicação:
+-- Esta consulta retorna todas as regras do filtro de conteúdo de uma sala, com base no 'room_id' fornecido como parâmetro ($1).
+
+-- name: InsertRoomFilterRule :one
+INSERT INTO room_filter_rules
+    ( "room_id", "pattern", "is_regex", "action" ) VALUES
+    ( $1, $2, $3, $4 )
+RETURNING "id", "room_id", "pattern", "is_regex", "action";
+
+-- Explicação:
+-- Esta instrução adiciona uma regra ao filtro de conteúdo de uma sala.
+-- 'pattern' é uma palavra ou expressão regular (quando 'is_regex' é true) e 'action' define o que fazer com a mensagem
+-- (mask, reject ou moderate). Após a inserção, retorna a regra completa.
+
+-- name: DeleteRoomFilterRule :execrows
+DELETE FROM room_filter_rules
+WHERE
+    id = $1 AND room_id = $2;
+
+-- Explicação:
+-- Esta instrução remove uma regra do filtro de conteúdo, identificada pelo 'id' ($1) e pela sala ($2).
+-- Retorna o número de linhas removidas para que seja possível detectar regras inexistentes.
@@ -1,76 +1,176 @@
 -- name: GetRoom :one
 SELECT
-    "id", "theme"
+    "id", "theme", "moderated", "version", "now_answering_id", "updated_at"
 FROM rooms
 WHERE id = $1;
 
 -- Explicação:
 -- Esta consulta busca uma sala (room) específica na tabela 'rooms', com base em um 'id' fornecido como parâmetro ($1).
--- Retorna as colunas 'id' e 'theme' da sala correspondente.
+-- Retorna as colunas 'id', 'theme', 'moderated' (se as mensagens passam por moderação), 'version', 'now_answering_id'
+-- (a mensagem sendo respondida no momento, se houver) e 'updated_at' da sala correspondente.
+-- A versão é incrementada a cada alteração da sala ou das suas mensagens e é usada nas ETags da API;
+-- 'updated_at' registra o momento da última dessas alterações, sem nunca voltar no tempo, e é usada no Last-Modified dos feeds.
 
 -- name: GetRooms :many
 SELECT
-    "id", "theme"
+    "id", "theme", "moderated", "version", "now_answering_id", "updated_at"
 FROM rooms;
 
 -- Explicação:
 -- Esta consulta retorna todas as salas (rooms) da tabela 'rooms'.
--- Retorna as colunas 'id' e 'theme' de todas as salas.
+-- Retorna as colunas 'id', 'theme', 'moderated', 'version', 'now_answering_id' e 'updated_at' de todas as salas.
 
 -- name: InsertRoom :one
 INSERT INTO rooms
-    ( "theme" ) VALUES
-    ( $1 )
+    ( "theme", "moderated" ) VALUES
+    ( $1, $2 )
 RETURNING "id";
 
 -- Explicação:
 -- Esta instrução insere uma nova sala (room) na tabela 'rooms'.
--- O tema da sala é fornecido como parâmetro ($1).
+-- O tema da sala e se ela é moderada são fornecidos como parâmetros ($1 e $2, respectivamente).
 -- Após a inserção, o comando retorna o 'id' da nova sala criada.
 
+-- name: SetRoomModerated :one
+UPDATE rooms
+SET
+    moderated = $2
+WHERE
+    id = $1
+RETURNING "id", "theme", "moderated", "version", "now_answering_id", "updated_at";
+
+-- Explicação:
+-- Esta instrução ativa ou desativa a moderação prévia das mensagens de uma sala.
+-- A sala é identificada pelo 'id' ($1) e o novo valor de 'moderated' é fornecido como parâmetro ($2).
+-- Após a atualização, retorna a sala com a nova versão, incrementada pelo gatilho rooms_bump_version.
+
+-- name: SetRoomNowAnswering :one
+UPDATE rooms
+SET
+    now_answering_id = m.id
+FROM messages m
+WHERE
+    rooms.id = sqlc.arg(room_id) AND m.id = sqlc.arg(message_id) AND m.room_id = rooms.id AND m.moderation_status = 'approved'
+RETURNING rooms."id", rooms."theme", rooms."moderated", rooms."version", rooms."now_answering_id", rooms."updated_at";
+
+-- Explicação:
+-- Esta instrução define a mensagem ('message_id') sendo respondida no momento na sala ('room_id'), substituindo a anterior.
+-- Como a coluna fica na sala, há no máximo uma mensagem sendo respondida por sala.
+-- Somente mensagens aprovadas da própria sala podem ser escolhidas; caso contrário nenhuma linha é retornada.
+
+-- name: ClearRoomNowAnswering :one
+UPDATE rooms
+SET
+    now_answering_id = NULL
+WHERE
+    id = $1
+RETURNING "id", "theme", "moderated", "version", "now_answering_id", "updated_at";
+
+-- Explicação:
+-- Esta instrução remove a indicação da mensagem sendo respondida na sala ($1) e retorna a sala atualizada.
+
+-- name: InsertRoomModeratorToken :exec
+INSERT INTO room_moderator_tokens
+    ( "room_id", "token_hash" ) VALUES
+    ( $1, $2 );
+
+-- Explicação:
+-- Esta instrução registra o hash do token de moderador de uma sala.
+-- O token em si nunca é armazenado, apenas o seu hash SHA-256 ($2).
+
+-- name: UpsertRoomModeratorToken :exec
+INSERT INTO room_moderator_tokens
+    ( "room_id", "token_hash" ) VALUES
+    ( $1, $2 )
+ON CONFLICT ("room_id") DO UPDATE SET token_hash = EXCLUDED.token_hash;
+
+-- Explicação:
+-- Esta instrução registra um novo hash de token de moderador para a sala ($1), substituindo o anterior se houver.
+-- É usada para emitir o token de salas criadas antes dos tokens de moderador e para trocar um token perdido ou vazado.
+
+-- name: GetRoomModeratorTokenHash :one
+SELECT
+    "token_hash"
+FROM room_moderator_tokens
+WHERE
+    room_id = $1;
+
+-- Explicação:
+-- Esta consulta retorna o hash do token de moderador da sala identificada por 'room_id' ($1).
+
 -- name: GetMessage :one
 SELECT
-    "id", "room_id", "message", "reaction_count", "answered"
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
 FROM messages
 WHERE
     id = $1;
 
 -- Explicação:
 -- Esta consulta busca uma mensagem específica na tabela 'messages', com base em um 'id' fornecido como parâmetro ($1).
--- Retorna as colunas 'id', 'room_id', 'message', 'reaction_count' (contagem de reações) e 'answered' (se a mensagem foi marcada como respondida).
+-- Retorna as colunas 'id', 'room_id', 'message', 'reaction_count' (contagem de reações), 'answered' (se a mensagem está respondida, derivada de 'status')
+-- 'moderation_status' (pending, approved ou rejected), 'attribution' (origem da mensagem enviada por um bot; vazia para a audiência),
+-- 'created_at', 'answered_at' (momento em que a mensagem foi marcada como respondida, se foi)
+-- 'version' (incrementada a cada alteração da mensagem), 'pinned' (se a mensagem foi fixada pelos moderadores)
+-- e 'status' (open, answering, answered, dismissed ou duplicate).
 
 -- name: GetRoomMessages :many
 SELECT
-    "id", "room_id", "message", "reaction_count", "answered"
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
 FROM messages
 WHERE
     room_id = $1;
 
 -- Explicação:
 -- Esta consulta retorna todas as mensagens de uma sala específica, com base no 'room_id' fornecido como parâmetro ($1).
--- Retorna as colunas 'id', 'room_id', 'message', 'reaction_count' e 'answered' de todas as mensagens pertencentes à sala.
+-- Retorna as colunas 'id', 'room_id', 'message', 'reaction_count', 'answered', 'moderation_status', 'attribution', 'created_at', 'answered_at', 'version', 'pinned' e 'status' de todas as mensagens pertencentes à sala.
+
+-- name: GetRoomMessagesByModerationStatus :many
+SELECT
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
+FROM messages
+WHERE
+    room_id = $1 AND moderation_status = $2;
+
+-- Explicação:
+-- Esta consulta retorna as mensagens de uma sala ($1) que estão em um determinado estado de moderação ($2).
+-- É usada para exibir à audiência apenas as mensagens aprovadas e aos moderadores a fila de mensagens pendentes.
 
 -- name: InsertMessage :one
 INSERT INTO messages
-    ( "room_id", "message" ) VALUES
-    ( $1, $2 )
+    ( "room_id", "message", "moderation_status", "attribution" ) VALUES
+    ( $1, $2, $3, $4 )
 RETURNING "id";
 
 -- Explicação:
 -- Esta instrução insere uma nova mensagem na tabela 'messages'.
--- O 'room_id' e o conteúdo da mensagem são fornecidos como parâmetros ($1 e $2, respectivamente).
+-- O 'room_id', o conteúdo da mensagem, o estado de moderação e a atribuição são fornecidos como parâmetros ($1 a $4, respectivamente).
+-- A atribuição identifica o bot ou a origem de mensagens enviadas com uma chave de API e é vazia para a audiência.
 -- Após a inserção, o comando retorna o 'id' da nova mensagem criada.
 
+-- name: UpdatePendingMessageModerationStatus :one
+UPDATE messages
+SET
+    moderation_status = $3
+WHERE
+    id = $1 AND room_id = $2 AND moderation_status = 'pending'
+RETURNING "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status";
+
+-- Explicação:
+-- Esta instrução aprova ou rejeita uma mensagem pendente, identificada pelo 'id' ($1) e pela sala ($2).
+-- Somente mensagens ainda pendentes são alteradas; caso contrário nenhuma linha é retornada.
+-- Após a atualização, retorna a mensagem completa para que ela possa ser enviada aos clientes.
+
 -- name: ReactToMessage :one
 UPDATE messages
 SET
     reaction_count = reaction_count + 1
 WHERE
-    id = $1
+    id = $1 AND room_id = $2 AND moderation_status = 'approved'
 RETURNING reaction_count;
 
 -- Explicação:
--- Esta instrução incrementa a contagem de reações (reaction_count) de uma mensagem específica, com base no 'id' fornecido como parâmetro ($1).
+-- Esta instrução incrementa a contagem de reações (reaction_count) de uma mensagem, identificada pelo 'id' ($1) e pela sala ($2).
+-- Somente mensagens aprovadas recebem reações; para mensagens pendentes, rejeitadas ou ocultadas nenhuma linha é retornada.
 -- Após a atualização, retorna o novo valor da contagem de reações.
 
 -- name: RemoveReactionFromMessage :one
@@ -78,21 +178,52 @@ UPDATE messages
 SET
     reaction_count = reaction_count - 1
 WHERE
-    id = $1
+    id = $1 AND room_id = $2 AND moderation_status = 'approved'
 RETURNING reaction_count;
 
 -- Explicação:
--- Esta instrução decrementa a contagem de reações (reaction_count) de uma mensagem específica, com base no 'id' fornecido como parâmetro ($1).
+-- Esta instrução decrementa a contagem de reações (reaction_count) de uma mensagem, identificada pelo 'id' ($1) e pela sala ($2).
+-- Somente mensagens aprovadas recebem reações; para mensagens pendentes, rejeitadas ou ocultadas nenhuma linha é retornada.
 -- Após a atualização, retorna o novo valor da contagem de reações.
 
--- name: MarkMessageAsAnswered :exec
+-- name: UpdateMessageStatus :one
 UPDATE messages
 SET
-    answered = true
+    status = sqlc.arg(status),
+    answered_at = CASE WHEN sqlc.arg(status) = 'answered' THEN COALESCE(answered_at, now()) END
 WHERE
-    id = $1;
+    id = sqlc.arg(id) AND room_id = sqlc.arg(room_id) AND status = sqlc.arg(old_status)
+RETURNING "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status";
+
+-- Explicação:
+-- Esta instrução altera o estado de resposta ('status') de uma mensagem, identificada pelo 'id' e pela sala ('room_id').
+-- A mensagem só é alterada se ainda estiver no estado 'old_status'; caso contrário nenhuma linha é retornada,
+-- o que evita que duas alterações simultâneas sejam validadas a partir do mesmo estado.
+-- 'answered_at' guarda o momento em que a mensagem foi respondida e é limpo quando ela deixa de estar respondida.
+-- Após a atualização, retorna a mensagem completa.
+
+-- name: SetMessagePinned :one
+UPDATE messages
+SET
+    pinned = sqlc.arg(pinned)::boolean
+WHERE
+    id = sqlc.arg(id) AND room_id = sqlc.arg(room_id) AND (moderation_status = 'approved' OR NOT sqlc.arg(pinned)::boolean)
+RETURNING "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status";
+
+-- Explicação:
+-- Esta instrução fixa ou desafixa ('pinned') uma mensagem, identificada pelo 'id' e pela sala ('room_id').
+-- Somente mensagens aprovadas podem ser fixadas; qualquer mensagem pode ser desafixada, inclusive uma que foi ocultada.
+-- Após a atualização, retorna a mensagem completa.
+
+-- name: GetRoomHighlightedMessages :many
+SELECT
+    "id", "room_id", "message", "reaction_count", "answered", "moderation_status", "attribution", "created_at", "answered_at", "version", "pinned", "status"
+FROM messages
+WHERE
+    room_id = $1 AND moderation_status = 'approved'
+    AND (pinned OR id = (SELECT now_answering_id FROM rooms WHERE rooms.id = $1))
+ORDER BY created_at;
 
 -- Explicação:
--- Esta instrução marca uma mensagem como respondida, alterando o valor de 'answered' para true.
--- A mensagem é identificada pelo 'id' fornecido como parâmetro ($1).
--- Diferente das outras instruções, esta não retorna nenhum valor (uso do sufixo ':exec').
+-- Esta consulta retorna as mensagens aprovadas em destaque na sala ($1): as fixadas e a que está sendo respondida.
+-- As mensagens são ordenadas da mais antiga para a mais recente.
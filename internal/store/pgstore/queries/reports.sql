@@ -0,0 +1,55 @@
+-- name: InsertMessageReport :execrows
+INSERT INTO message_reports
+    ( "message_id", "reporter", "reason" ) VALUES
+    ( $1, $2, $3 )
+ON CONFLICT (message_id, reporter) DO NOTHING;
+
+-- Explicação:
+-- Esta instrução registra uma denúncia de uma mensagem ($1) feita por um IP ($2) com um motivo ($3).
+-- Cada denunciante só é contado uma vez por mensagem, mesmo depois da revisão; denúncias repetidas não alteram nenhuma linha.
+
+-- name: CountMessageReports :one
+SELECT
+    COUNT(*)
+FROM message_reports
+WHERE
+    message_id = $1 AND reviewed_at IS NULL;
+
+-- Explicação:
+-- Esta consulta retorna o número de denúncias de uma mensagem ($1) ainda não revisadas por um moderador.
+
+-- name: MarkMessageReportsReviewed :exec
+UPDATE message_reports
+SET
+    reviewed_at = now()
+WHERE
+    message_id = $1 AND reviewed_at IS NULL;
+
+-- Explicação:
+-- Esta instrução marca como revisadas as denúncias de uma mensagem ($1), quando um moderador a aprova ou rejeita.
+-- Denúncias revisadas não contam para a ocultação automática nem aparecem na lista de mensagens denunciadas.
+
+-- name: HideMessage :execrows
+UPDATE messages
+SET
+    moderation_status = 'pending'
+WHERE
+    id = $1 AND moderation_status = 'approved';
+
+-- Explicação:
+-- Esta instrução oculta uma mensagem aprovada, devolvendo-a à fila de moderação.
+-- Retorna o número de linhas alteradas para que a ocultação seja notificada apenas uma vez.
+
+-- name: GetRoomReportedMessages :many
+SELECT
+    m."id", m."message", m."moderation_status", COUNT(r."id") AS report_count
+FROM messages m
+JOIN message_reports r ON r.message_id = m.id AND r.reviewed_at IS NULL
+WHERE
+    m.room_id = $1
+GROUP BY m.id
+ORDER BY report_count DESC;
+
+-- Explicação:
+-- Esta consulta lista as mensagens denunciadas de uma sala ($1), com o número de denúncias não revisadas de cada uma.
+-- As mensagens mais denunciadas aparecem primeiro.
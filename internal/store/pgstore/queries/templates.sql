@@ -0,0 +1,35 @@
+-- name: CopyRoomFilterRules :exec
+INSERT INTO room_filter_rules
+    ( "room_id", "pattern", "is_regex", "action" )
+SELECT
+    sqlc.arg(target_room_id)::uuid, "pattern", "is_regex", "action"
+FROM room_filter_rules
+WHERE
+    room_id = sqlc.arg(source_room_id);
+
+-- Explicação:
+-- Esta instrução copia as regras do filtro de conteúdo da sala de origem ('source_room_id') para a sala nova
+-- ('target_room_id'). É usada ao clonar uma sala.
+
+-- name: CopyUnansweredMessages :execrows
+INSERT INTO messages
+    ( "room_id", "message", "reaction_count", "moderation_status", "attribution" )
+SELECT
+    sqlc.arg(target_room_id)::uuid, "message", "reaction_count", "moderation_status", "attribution"
+FROM messages
+WHERE
+    room_id = sqlc.arg(source_room_id) AND status IN ('open', 'answering') AND moderation_status <> 'rejected';
+
+-- Explicação:
+-- Esta instrução copia as perguntas ainda não respondidas (abertas ou em resposta, e não rejeitadas) da sala de origem
+-- para a sala nova, mantendo a contagem de reações, o estado de moderação e a atribuição.
+-- As cópias voltam a ficar abertas. Retorna o número de mensagens copiadas.
+
+-- name: ImportMessage :exec
+INSERT INTO messages
+    ( "room_id", "message", "reaction_count", "status", "moderation_status", "attribution" ) VALUES
+    ( $1, $2, $3, $4, $5, $6 );
+
+-- Explicação:
+-- Esta instrução insere uma mensagem importada com todos os seus campos, inclusive a contagem de reações
+-- e o estado de resposta, preservando o conteúdo de uma exportação.
@@ -0,0 +1,23 @@
+-- name: LockRoomVersion :one
+SELECT
+    "version"
+FROM rooms
+WHERE
+    id = $1
+FOR UPDATE;
+
+-- Explicação:
+-- Esta consulta retorna a versão atual da sala ($1) e bloqueia a linha até o fim da transação.
+-- É usada para verificar o cabeçalho If-Match antes de uma alteração, sem que outra alteração ocorra no intervalo.
+
+-- name: LockMessageVersion :one
+SELECT
+    "version"
+FROM messages
+WHERE
+    id = $1 AND room_id = $2
+FOR UPDATE;
+
+-- Explicação:
+-- Esta consulta retorna a versão atual de uma mensagem, identificada pelo 'id' ($1) e pela sala ($2),
+-- e bloqueia a linha até o fim da transação, como LockRoomVersion.
@@ -0,0 +1,101 @@
+-- name: GetRoomWebhooks :many
+SELECT
+    "id", "room_id", "url", "event_kinds", "created_at"
+FROM room_webhooks
+WHERE
+    room_id = $1;
+
+-- Explicação:
+-- Esta consulta retorna os webhooks cadastrados em uma sala ($1), sem o segredo usado nas assinaturas.
+
+-- name: InsertRoomWebhook :one
+INSERT INTO room_webhooks
+    ( "room_id", "url", "secret", "event_kinds" ) VALUES
+    ( $1, $2, $3, $4 )
+RETURNING "id", "room_id", "url", "secret", "event_kinds", "created_at";
+
+-- Explicação:
+-- Esta instrução cadastra um webhook em uma sala. 'event_kinds' lista os tipos de evento enviados;
+-- uma lista vazia envia todos. Após a inserção, retorna o webhook completo, inclusive o segredo.
+
+-- name: DeleteRoomWebhook :execrows
+DELETE FROM room_webhooks
+WHERE
+    id = $1 AND room_id = $2;
+
+-- Explicação:
+-- Esta instrução remove um webhook, identificado pelo 'id' ($1) e pela sala ($2), junto com as suas entregas.
+-- Retorna o número de linhas removidas para que seja possível detectar webhooks inexistentes.
+
+-- name: InsertWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries
+    ( "webhook_id", "event_kind", "payload" )
+SELECT
+    "id", sqlc.arg(event_kind)::text, sqlc.arg(payload)::text
+FROM room_webhooks
+WHERE
+    room_id = sqlc.arg(room_id) AND (cardinality(event_kinds) = 0 OR sqlc.arg(event_kind)::text = ANY(event_kinds));
+
+-- Explicação:
+-- Esta instrução grava no outbox uma entrega do evento para cada webhook da sala interessado no seu tipo.
+-- Retorna o número de entregas criadas.
+
+-- name: ClaimWebhookDeliveries :many
+WITH due AS (
+    SELECT
+        "id"
+    FROM webhook_deliveries
+    WHERE
+        status = 'pending' AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+), claimed AS (
+    UPDATE webhook_deliveries d
+    SET
+        next_attempt_at = now() + make_interval(secs => sqlc.arg(lease_seconds))
+    FROM due
+    WHERE
+        d.id = due.id
+    RETURNING d."id", d."webhook_id", d."event_kind", d."payload", d."attempts"
+)
+SELECT
+    c."id", c."webhook_id", c."event_kind", c."payload", c."attempts", w."url", w."secret"
+FROM claimed c
+JOIN room_webhooks w ON w.id = c.webhook_id;
+
+-- Explicação:
+-- Esta consulta reserva até 'batch_size' entregas pendentes cujo horário de tentativa já chegou, adiando-as por
+-- 'lease_seconds' para que outras instâncias não as enviem ao mesmo tempo. O prazo precisa cobrir o envio de todo o lote,
+-- ou entregas ainda na fila seriam reservadas de novo por outra instância. Se o processo cair durante o envio, a entrega
+-- volta a ficar disponível depois desse prazo. Retorna as entregas com a URL e o segredo do webhook.
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET
+    attempts = attempts + 1,
+    status = $2,
+    last_status_code = $3,
+    last_error = $4,
+    next_attempt_at = $5,
+    delivered_at = CASE WHEN $2 = 'delivered' THEN now() ELSE delivered_at END
+WHERE
+    id = $1;
+
+-- Explicação:
+-- Esta instrução registra o resultado de uma tentativa de entrega: o novo estado ('pending' para tentar de novo
+-- em 'next_attempt_at', 'delivered' ou 'failed'), o código de status HTTP e a mensagem de erro, se houver.
+
+-- name: GetRoomWebhookDeliveries :many
+SELECT
+    d."id", d."webhook_id", d."event_kind", d."payload", d."status", d."attempts", d."last_status_code", d."last_error",
+    d."next_attempt_at", d."delivered_at", d."created_at"
+FROM webhook_deliveries d
+JOIN room_webhooks w ON w.id = d.webhook_id
+WHERE
+    w.room_id = $1
+ORDER BY d.created_at DESC
+LIMIT $2;
+
+-- Explicação:
+-- Esta consulta retorna o registro das entregas mais recentes dos webhooks de uma sala ($1), limitado a $2 linhas.
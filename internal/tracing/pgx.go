@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer implementa pgx.QueryTracer criando um span para cada consulta.
+// Consultas geradas pelo sqlc são identificadas pelo comentário "-- name: Nome :tipo".
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+// queryName extrai o nome da consulta do comentário inserido pelo sqlc.
+func queryName(sql string) string {
+	rest, ok := strings.CutPrefix(sql, "-- name: ")
+	if !ok {
+		return "query"
+	}
+
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
+
+// TraceQueryStart implementa pgx.QueryTracer.
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name := queryName(data.SQL)
+	ctx, _ = Tracer.Start(ctx, "db "+name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(name),
+		semconv.DBQueryText(data.SQL),
+	))
+	return ctx
+}
+
+// TraceQueryEnd implementa pgx.QueryTracer.
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+}
@@ -0,0 +1,65 @@
+// Package tracing configura o OpenTelemetry e instrumenta as requisições HTTP, as consultas ao banco e os broadcasts.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exportadores de spans suportados por Setup
+const (
+	ExporterNone   = ""       // Tracing desativado; spans não são exportados
+	ExporterOTLP   = "otlp"   // Exporta via OTLP/HTTP (endpoint em OTEL_EXPORTER_OTLP_ENDPOINT, padrão localhost:4318)
+	ExporterStdout = "stdout" // Escreve os spans na saída padrão, útil em desenvolvimento
+)
+
+// Tracer é o tracer usado pela instrumentação do servidor.
+// Ele delega ao provider global, portanto pode ser usado antes ou sem a chamada a Setup.
+var Tracer = otel.Tracer("github.com/joao-ressel/go-server")
+
+// Setup configura o provider global de spans com o exportador informado e a propagação W3C (traceparent e baggage).
+// Retorna uma função que envia os spans pendentes e encerra o provider.
+func Setup(ctx context.Context, exporter string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName("wsrs")))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(spanExporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Detach retorna um contexto sem prazo nem cancelamento que mantém apenas o span de ctx.
+// É usado por trabalhos que continuam após o fim da requisição, como os broadcasts.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
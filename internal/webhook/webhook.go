@@ -0,0 +1,320 @@
+// Package webhook entrega os eventos das salas aos webhooks cadastrados pelos moderadores.
+//
+// Os eventos são gravados na tabela webhook_deliveries (outbox) na mesma transação da alteração que os produziu,
+// para que só sejam entregues se a alteração for confirmada. Um Dispatcher reserva as entregas pendentes, faz um POST
+// assinado para a URL do webhook e reagenda as que falharem com backoff exponencial. As entregas de um lote são enviadas
+// por vários workers, para que um receptor lento não atrase os demais. Como a fila fica no banco de dados,
+// as entregas sobrevivem a reinícios do servidor e várias instâncias podem despachá-las ao mesmo tempo.
+//
+// Cada requisição leva os cabeçalhos:
+//
+//	X-Wsrs-Event:     tipo do evento, como "message_created"
+//	X-Wsrs-Delivery:  ID da entrega, o mesmo em todas as tentativas
+//	X-Wsrs-Timestamp: momento da tentativa, em segundos desde a época Unix
+//	X-Wsrs-Signature: "sha256=" seguido do HMAC-SHA256 em hexadecimal de "<timestamp>.<corpo>", com o segredo do webhook
+//
+// O receptor deve recalcular a assinatura com Sign, compará-la em tempo constante e recusar timestamps antigos.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/joao-ressel/go-server/internal/metrics"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+)
+
+// Estados de uma entrega
+const (
+	StatusPending   = "pending"   // Aguardando a primeira tentativa ou uma nova tentativa
+	StatusDelivered = "delivered" // Aceita pelo receptor com um código 2xx
+	StatusFailed    = "failed"    // Esgotou as tentativas
+)
+
+// Cabeçalhos enviados em cada entrega
+const (
+	HeaderEvent     = "X-Wsrs-Event"
+	HeaderDelivery  = "X-Wsrs-Delivery"
+	HeaderTimestamp = "X-Wsrs-Timestamp"
+	HeaderSignature = "X-Wsrs-Signature"
+)
+
+// maxErrorLength limita o tamanho da mensagem de erro gravada no registro de entregas.
+const maxErrorLength = 512
+
+// recordTimeout é o prazo para gravar o resultado de uma tentativa.
+const recordTimeout = 5 * time.Second
+
+// leaseMargin é a folga da reserva sobre o tempo máximo de envio do lote.
+const leaseMargin = 30 * time.Second
+
+// Store fornece as entregas pendentes e registra o resultado de cada tentativa.
+type Store interface {
+	ClaimWebhookDeliveries(ctx context.Context, arg pgstore.ClaimWebhookDeliveriesParams) ([]pgstore.ClaimWebhookDeliveriesRow, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg pgstore.RecordWebhookDeliveryAttemptParams) error
+}
+
+// Config controla o ritmo e as tentativas do Dispatcher.
+type Config struct {
+	PollInterval         time.Duration // Intervalo entre as buscas por entregas pendentes
+	Timeout              time.Duration // Prazo de cada requisição ao receptor
+	BaseBackoff          time.Duration // Espera antes da segunda tentativa; dobra a cada falha
+	MaxBackoff           time.Duration // Espera máxima entre tentativas
+	MaxAttempts          int           // Tentativas antes de a entrega ser marcada como falha
+	BatchSize            int           // Entregas reservadas por busca
+	Workers              int           // Entregas do lote enviadas ao mesmo tempo
+	Lease                time.Duration // Prazo da reserva de um lote; nunca menor que o tempo máximo de envio do lote (veja minLease)
+	AllowPrivateNetworks bool          // Permite URLs em endereços privados ou de loopback, útil em desenvolvimento
+}
+
+// DefaultConfig é a configuração usada pelos campos não preenchidos em NewDispatcher.
+var DefaultConfig = Config{
+	PollInterval: time.Second,
+	Timeout:      10 * time.Second,
+	BaseBackoff:  10 * time.Second,
+	MaxBackoff:   time.Hour,
+	MaxAttempts:  10,
+	BatchSize:    50,
+	Workers:      10,
+}
+
+// Dispatcher envia as entregas pendentes do outbox aos receptores.
+type Dispatcher struct {
+	store  Store
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewDispatcher cria um Dispatcher; campos zerados de cfg recebem os valores de DefaultConfig.
+func NewDispatcher(store Store, cfg Config) *Dispatcher {
+	cfg.PollInterval = orDefault(cfg.PollInterval, DefaultConfig.PollInterval)
+	cfg.Timeout = orDefault(cfg.Timeout, DefaultConfig.Timeout)
+	cfg.BaseBackoff = orDefault(cfg.BaseBackoff, DefaultConfig.BaseBackoff)
+	cfg.MaxBackoff = orDefault(cfg.MaxBackoff, DefaultConfig.MaxBackoff)
+	cfg.MaxAttempts = orDefault(cfg.MaxAttempts, DefaultConfig.MaxAttempts)
+	cfg.BatchSize = orDefault(cfg.BatchSize, DefaultConfig.BatchSize)
+	cfg.Workers = min(orDefault(cfg.Workers, DefaultConfig.Workers), cfg.BatchSize)
+	cfg.Lease = max(cfg.Lease, minLease(cfg))
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = denyPrivateNetworks
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // O proxy impediria a verificação do endereço de destino
+	transport.DialContext = dialer.DialContext
+
+	return &Dispatcher{
+		store: store,
+		cfg:   cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse // Redirecionamentos contam como falha
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// minLease retorna o menor prazo de reserva seguro para cfg: cada worker envia até BatchSize/Workers entregas do lote,
+// uma depois da outra, e cada uma leva no máximo Timeout para o envio e recordTimeout para gravar o resultado.
+// Com um prazo menor, as últimas entregas de um worker ainda estariam na fila quando a reserva vencesse, e outra
+// instância as enviaria de novo.
+func minLease(cfg Config) time.Duration {
+	perWorker := (cfg.BatchSize + cfg.Workers - 1) / cfg.Workers
+	return time.Duration(perWorker)*(cfg.Timeout+recordTimeout) + leaseMargin
+}
+
+func orDefault[T comparable](v, fallback T) T {
+	var zero T
+	if v == zero {
+		return fallback
+	}
+	return v
+}
+
+// Run despacha as entregas pendentes até que ctx seja cancelado.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Enquanto houver lotes cheios, continua sem esperar o próximo tick
+		for n := d.cfg.BatchSize; n == d.cfg.BatchSize && ctx.Err() == nil; {
+			n = d.dispatchBatch(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchBatch reserva um lote de entregas e o envia com até Workers entregas ao mesmo tempo,
+// retornando quantas foram reservadas.
+func (d *Dispatcher) dispatchBatch(ctx context.Context) int {
+	deliveries, err := d.store.ClaimWebhookDeliveries(ctx, pgstore.ClaimWebhookDeliveriesParams{
+		BatchSize:    int32(d.cfg.BatchSize),
+		LeaseSeconds: d.cfg.Lease.Seconds(),
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("failed to claim webhook deliveries", "error", err)
+		}
+		return 0
+	}
+
+	queue := make(chan pgstore.ClaimWebhookDeliveriesRow)
+	var wg sync.WaitGroup
+	for range min(d.cfg.Workers, len(deliveries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range queue {
+				d.deliver(ctx, delivery)
+			}
+		}()
+	}
+	for _, delivery := range deliveries {
+		queue <- delivery
+	}
+	close(queue)
+	wg.Wait()
+
+	return len(deliveries)
+}
+
+// deliver faz uma tentativa de entrega e registra o resultado.
+func (d *Dispatcher) deliver(ctx context.Context, delivery pgstore.ClaimWebhookDeliveriesRow) {
+	statusCode, err := d.send(ctx, delivery)
+	if err != nil && ctx.Err() != nil {
+		return // Interrompida pelo encerramento; a reserva expira e a entrega é repetida sem contar a tentativa
+	}
+	attempts := int(delivery.Attempts) + 1
+
+	params := pgstore.RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         StatusDelivered,
+		LastStatusCode: int32(statusCode),
+		NextAttemptAt:  pgtype.Timestamptz{Time: d.now(), Valid: true},
+	}
+	switch {
+	case err == nil:
+		metrics.WebhookDeliveries.WithLabelValues(StatusDelivered).Inc()
+	case attempts >= d.cfg.MaxAttempts:
+		params.Status = StatusFailed
+		params.LastError = truncate(err.Error(), maxErrorLength)
+		metrics.WebhookDeliveries.WithLabelValues(StatusFailed).Inc()
+		slog.Warn("webhook delivery failed permanently", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookID, "attempts", attempts, "error", err)
+	default:
+		params.Status = StatusPending
+		params.LastError = truncate(err.Error(), maxErrorLength)
+		params.NextAttemptAt.Time = d.now().Add(d.backoff(attempts))
+		metrics.WebhookDeliveries.WithLabelValues("retry").Inc()
+	}
+
+	// O resultado é gravado mesmo que ctx tenha sido cancelado durante o envio
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if err := d.store.RecordWebhookDeliveryAttempt(recordCtx, params); err != nil {
+		slog.Error("failed to record webhook delivery attempt", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// send faz o POST assinado ao receptor. Qualquer código fora da faixa 2xx é tratado como falha.
+func (d *Dispatcher) send(ctx context.Context, delivery pgstore.ClaimWebhookDeliveriesRow) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wsrs-webhook/1")
+	req.Header.Set(HeaderEvent, delivery.EventKind)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Permite reaproveitar a conexão
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff retorna a espera antes da próxima tentativa: BaseBackoff dobrado a cada falha, limitado a MaxBackoff,
+// com uma variação aleatória de até 20% para que entregas que falharam juntas não sejam repetidas juntas.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.MaxBackoff
+	if shift := attempts - 1; shift < 32 {
+		wait = min(d.cfg.BaseBackoff<<shift, d.cfg.MaxBackoff)
+	}
+	if wait <= 0 {
+		wait = d.cfg.MaxBackoff // Estouro do deslocamento
+	}
+	return wait - time.Duration(rand.Int64N(int64(wait)/5+1))
+}
+
+// Sign calcula a assinatura de uma entrega: o HMAC-SHA256 em hexadecimal de "<timestamp>.<body>".
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// errPrivateNetwork indica que a URL do webhook aponta para um endereço que não é público.
+var errPrivateNetwork = errors.New("webhook address is not public")
+
+// denyPrivateNetworks recusa conexões com endereços de loopback, privados, link-local e não especificados.
+// A verificação é feita no endereço já resolvido, para que um nome DNS não contorne a restrição.
+func denyPrivateNetworks(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsUnspecified() || addr.IsMulticast() {
+		return errPrivateNetwork
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") // O corte pode ter separado um caractere multibyte
+}
@@ -0,0 +1,375 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/store/pgstore"
+	"github.com/joao-ressel/go-server/internal/store/pgstore/pgstoretest"
+)
+
+// receiver é um receptor de webhooks que responde com os códigos de status informados, em ordem;
+// depois do último, repete-o.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	return newSlowReceiver(t, nil, statuses...)
+}
+
+// newSlowReceiver cria um receptor que, antes de responder cada requisição, aguarda wait, se não for nil.
+func newSlowReceiver(t *testing.T, wait func(), statuses ...int) *receiver {
+	rec := &receiver{statuses: statuses}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if wait != nil {
+			wait()
+		}
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		status := rec.statuses[min(len(rec.requests), len(rec.statuses)-1)]
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+// received retorna as requisições recebidas e os seus corpos.
+func (rec *receiver) received() ([]*http.Request, [][]byte) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.requests, rec.bodies
+}
+
+// outbox é uma sala com um webhook que aponta para url e uma entrega pendente.
+type outbox struct {
+	store  pgstore.Querier
+	roomID uuid.UUID
+}
+
+const (
+	testSecret  = "secret"
+	testEvent   = "message_created"
+	testPayload = `{"version":2,"kind":"message_created"}`
+)
+
+// newOutbox cria o outbox no banco de dados em memória de pgstoretest.
+func newOutbox(t *testing.T, url string) outbox {
+	return newOutboxIn(t, pgstoretest.NewMemory(), url)
+}
+
+// newOutboxIn cria o outbox em store.
+func newOutboxIn(t *testing.T, store pgstore.Querier, url string) outbox {
+	ctx := context.Background()
+
+	roomID, err := store.InsertRoom(ctx, pgstore.InsertRoomParams{Theme: "webhooks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.InsertRoomWebhook(ctx, pgstore.InsertRoomWebhookParams{RoomID: roomID, Url: url, Secret: testSecret}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.InsertWebhookDeliveries(ctx, pgstore.InsertWebhookDeliveriesParams{RoomID: roomID, EventKind: testEvent, Payload: testPayload}); err != nil {
+		t.Fatal(err)
+	}
+	return outbox{store: store, roomID: roomID}
+}
+
+// addWebhook cadastra na sala mais um webhook, que assina os tipos de evento informados.
+func (o outbox) addWebhook(t *testing.T, url string, kinds ...string) {
+	t.Helper()
+	if _, err := o.store.InsertRoomWebhook(context.Background(), pgstore.InsertRoomWebhookParams{RoomID: o.roomID, Url: url, Secret: testSecret, EventKinds: kinds}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// enqueue grava n eventos do tipo informado, com uma entrega para cada webhook interessado.
+func (o outbox) enqueue(t *testing.T, kind string, n int) {
+	t.Helper()
+	for range n {
+		if _, err := o.store.InsertWebhookDeliveries(context.Background(), pgstore.InsertWebhookDeliveriesParams{RoomID: o.roomID, EventKind: kind, Payload: testPayload}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// deliveries retorna o estado atual das entregas da sala.
+func (o outbox) deliveries(t *testing.T) []pgstore.WebhookDelivery {
+	t.Helper()
+	deliveries, err := o.store.GetRoomWebhookDeliveries(context.Background(), pgstore.GetRoomWebhookDeliveriesParams{RoomID: o.roomID, Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+// delivery retorna o estado atual da única entrega da sala.
+func (o outbox) delivery(t *testing.T) pgstore.WebhookDelivery {
+	t.Helper()
+	deliveries := o.deliveries(t)
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+// newTestDispatcher cria um Dispatcher cujo relógio está uma hora no passado, para que as novas tentativas,
+// agendadas por esse relógio, já estejam vencidas para o outbox, que usa o relógio real.
+func newTestDispatcher(o outbox, cfg Config) (*Dispatcher, time.Time) {
+	cfg.AllowPrivateNetworks = true // O receptor escuta em 127.0.0.1
+	d := NewDispatcher(o.store, cfg)
+	now := time.Now().Add(-time.Hour).Truncate(time.Second)
+	d.now = func() time.Time { return now }
+	return d, now
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	rec := newReceiver(t, http.StatusNoContent)
+	o := newOutbox(t, rec.URL)
+	d, now := newTestDispatcher(o, Config{})
+
+	if n := d.dispatchBatch(context.Background()); n != 1 {
+		t.Fatalf("dispatched %d deliveries, want 1", n)
+	}
+
+	requests, bodies := rec.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req, body := requests[0], bodies[0]
+	delivery := o.delivery(t)
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	for header, want := range map[string]string{
+		"Content-Type":  "application/json",
+		HeaderEvent:     testEvent,
+		HeaderDelivery:  delivery.ID.String(),
+		HeaderTimestamp: timestamp,
+		HeaderSignature: "sha256=" + Sign(testSecret, timestamp, []byte(testPayload)),
+	} {
+		if got := req.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if string(body) != testPayload {
+		t.Errorf("body = %s, want %s", body, testPayload)
+	}
+
+	if delivery.Status != StatusDelivered || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusNoContent || !delivery.DeliveredAt.Valid {
+		t.Errorf("delivery = %+v, want delivered after 1 attempt with status 204", delivery)
+	}
+	if n := d.dispatchBatch(context.Background()); n != 0 {
+		t.Errorf("dispatched %d deliveries after success, want 0", n)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	rec := newReceiver(t, http.StatusInternalServerError)
+	o := newOutbox(t, rec.URL)
+	cfg := Config{BaseBackoff: time.Minute, MaxBackoff: 3 * time.Minute, MaxAttempts: 4}
+	d, now := newTestDispatcher(o, cfg)
+
+	// Esperas antes das tentativas 2, 3 e 4: BaseBackoff dobrado a cada falha e limitado a MaxBackoff
+	waits := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}
+	for attempt, wait := range waits {
+		if n := d.dispatchBatch(context.Background()); n != 1 {
+			t.Fatalf("attempt %d: dispatched %d deliveries, want 1", attempt+1, n)
+		}
+
+		delivery := o.delivery(t)
+		if delivery.Status != StatusPending || int(delivery.Attempts) != attempt+1 {
+			t.Fatalf("attempt %d: delivery = %+v, want pending", attempt+1, delivery)
+		}
+		if delivery.LastStatusCode != http.StatusInternalServerError || delivery.LastError != "unexpected status 500" {
+			t.Errorf("attempt %d: last status %d, error %q", attempt+1, delivery.LastStatusCode, delivery.LastError)
+		}
+
+		// A variação aleatória adianta a tentativa em até 20%
+		next := delivery.NextAttemptAt.Time.Sub(now)
+		if next > wait || next < wait-wait/5 {
+			t.Errorf("attempt %d: next attempt in %v, want between %v and %v", attempt+1, next, wait-wait/5, wait)
+		}
+	}
+
+	if n := d.dispatchBatch(context.Background()); n != 1 {
+		t.Fatalf("last attempt: dispatched %d deliveries, want 1", n)
+	}
+	delivery := o.delivery(t)
+	if delivery.Status != StatusFailed || int(delivery.Attempts) != cfg.MaxAttempts || delivery.DeliveredAt.Valid {
+		t.Errorf("delivery = %+v, want failed after %d attempts", delivery, cfg.MaxAttempts)
+	}
+
+	if n := d.dispatchBatch(context.Background()); n != 0 {
+		t.Errorf("dispatched %d deliveries after the last attempt, want 0", n)
+	}
+	if requests, _ := rec.received(); len(requests) != cfg.MaxAttempts {
+		t.Errorf("receiver got %d requests, want %d", len(requests), cfg.MaxAttempts)
+	}
+}
+
+func TestDispatcherDeliversAfterRetry(t *testing.T) {
+	rec := newReceiver(t, http.StatusServiceUnavailable, http.StatusOK)
+	o := newOutbox(t, rec.URL)
+	d, _ := newTestDispatcher(o, Config{MaxAttempts: 3})
+
+	for range 2 {
+		d.dispatchBatch(context.Background())
+	}
+
+	delivery := o.delivery(t)
+	if delivery.Status != StatusDelivered || delivery.Attempts != 2 || delivery.LastStatusCode != http.StatusOK {
+		t.Errorf("delivery = %+v, want delivered after 2 attempts", delivery)
+	}
+	requests, _ := rec.received()
+	if len(requests) != 2 {
+		t.Fatalf("receiver got %d requests, want 2", len(requests))
+	}
+	if first, retry := requests[0].Header.Get(HeaderDelivery), requests[1].Header.Get(HeaderDelivery); first != retry {
+		t.Errorf("retry used delivery ID %q, want %q", retry, first)
+	}
+}
+
+func TestDispatcherDeniesPrivateNetworks(t *testing.T) {
+	rec := newReceiver(t, http.StatusOK)
+	o := newOutbox(t, rec.URL)
+	d := NewDispatcher(o.store, Config{MaxAttempts: 1})
+
+	d.dispatchBatch(context.Background())
+
+	delivery := o.delivery(t)
+	if requests, _ := rec.received(); delivery.Status != StatusFailed || len(requests) != 0 {
+		t.Errorf("delivery = %+v with %d requests, want failed without requests", delivery, len(requests))
+	}
+}
+
+func TestDispatcherLeaseCoversBatch(t *testing.T) {
+	for _, cfg := range []Config{
+		{BatchSize: 10, Workers: 1, Timeout: time.Second, Lease: time.Second},
+		{BatchSize: 10, Workers: 3, Timeout: 2 * time.Second},
+		{},
+	} {
+		d := NewDispatcher(pgstoretest.NewMemory(), cfg)
+		perWorker := (d.cfg.BatchSize + d.cfg.Workers - 1) / d.cfg.Workers
+		if need := time.Duration(perWorker) * d.cfg.Timeout; d.cfg.Lease <= need {
+			t.Errorf("%+v: lease %v does not cover %d deliveries of %v", cfg, d.cfg.Lease, perWorker, d.cfg.Timeout)
+		}
+	}
+}
+
+func TestDispatcherSlowReceiverIsNotReclaimed(t *testing.T) {
+	checkSlowReceiverNotReclaimed(t, pgstoretest.NewMemory())
+}
+
+// TestDispatcherSlowReceiverIsNotReclaimedPostgres repete TestDispatcherSlowReceiverIsNotReclaimed com um banco de dados
+// real, em que a reserva das entregas depende de ClaimWebhookDeliveries (veja pgstoretest.EnvDatabaseURL).
+func TestDispatcherSlowReceiverIsNotReclaimedPostgres(t *testing.T) {
+	checkSlowReceiverNotReclaimed(t, pgstore.New(pgstoretest.New(t)))
+}
+
+func checkSlowReceiverNotReclaimed(t *testing.T, store pgstore.Querier) {
+	rec := newSlowReceiver(t, func() { time.Sleep(50 * time.Millisecond) }, http.StatusNoContent)
+	o := newOutboxIn(t, store, rec.URL)
+	o.enqueue(t, testEvent, 5)
+	cfg := Config{BatchSize: 6, Workers: 2, Timeout: time.Second}
+	first, _ := newTestDispatcher(o, cfg)
+	second, _ := newTestDispatcher(o, cfg) // Outra instância, que busca entregas enquanto a primeira envia o lote
+
+	done := make(chan int)
+	go func() { done <- first.dispatchBatch(context.Background()) }()
+
+	reclaimed := 0
+	for running := true; running; {
+		select {
+		case n := <-done:
+			if n != 6 {
+				t.Errorf("dispatched %d deliveries, want 6", n)
+			}
+			running = false
+		case <-time.After(10 * time.Millisecond):
+			reclaimed += second.dispatchBatch(context.Background())
+		}
+	}
+	if reclaimed != 0 {
+		t.Errorf("another dispatcher claimed %d deliveries of the batch in flight", reclaimed)
+	}
+
+	requests, _ := rec.received()
+	ids := make(map[string]bool)
+	for _, req := range requests {
+		ids[req.Header.Get(HeaderDelivery)] = true
+	}
+	if len(requests) != 6 || len(ids) != 6 {
+		t.Errorf("receiver got %d requests for %d deliveries, want each of the 6 deliveries once", len(requests), len(ids))
+	}
+	for _, delivery := range o.deliveries(t) {
+		if delivery.Status != StatusDelivered || delivery.Attempts != 1 {
+			t.Errorf("delivery = %+v, want delivered after 1 attempt", delivery)
+		}
+	}
+}
+
+func TestDispatcherDeliversConcurrently(t *testing.T) {
+	release := make(chan struct{})
+	var started atomic.Bool
+	slow := newSlowReceiver(t, func() {
+		if !started.Swap(true) {
+			<-release
+		}
+	}, http.StatusNoContent)
+	fast := newReceiver(t, http.StatusNoContent)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+
+	// A primeira entrega do lote vai ao receptor lento, que só responde quando liberado
+	o := newOutbox(t, slow.URL)
+	o.addWebhook(t, fast.URL, "message_hidden")
+	o.enqueue(t, "message_hidden", 3)
+	d, _ := newTestDispatcher(o, Config{BatchSize: 7, Workers: 2})
+
+	done := make(chan int)
+	go func() { done <- d.dispatchBatch(context.Background()) }()
+
+	// O receptor lento ocupa um worker; o outro entrega as demais sem esperar por ele
+	deadline := time.After(5 * time.Second)
+	for {
+		fastRequests, _ := fast.received()
+		slowRequests, _ := slow.received()
+		if len(fastRequests) == 3 && len(slowRequests) == 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("delivered %d fast and %d slow deliveries while the first one was blocked, want 3 and 3", len(fastRequests), len(slowRequests))
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	close(release)
+	if n := <-done; n != 7 {
+		t.Errorf("dispatched %d deliveries, want 7", n)
+	}
+	if requests, _ := slow.received(); len(requests) != 4 {
+		t.Errorf("slow receiver got %d requests, want 4", len(requests))
+	}
+}
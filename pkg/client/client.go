@@ -0,0 +1,181 @@
+// Package client é o SDK em Go para a API REST e para os eventos de WebSocket do servidor.
+//
+// Os tipos das respostas e dos eventos são definidos aqui, no formato JSON da versão 2 da API,
+// para que o SDK não dependa dos pacotes internos do servidor.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client acessa a versão 2 da API de um servidor.
+type Client struct {
+	baseURL          *url.URL
+	httpClient       *http.Client
+	participantToken string
+	moderatorToken   string
+	apiKey           string
+	backoff          Backoff
+}
+
+// Option altera a configuração de um Client.
+type Option func(*Client)
+
+// WithHTTPClient define o cliente HTTP usado nas requisições REST e no handshake do WebSocket.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithParticipantToken identifica o participante com o token emitido por CreateParticipant,
+// usado pelo servidor nos limites de requisições e banimentos.
+func WithParticipantToken(token string) Option {
+	return func(c *Client) {
+		c.participantToken = token
+	}
+}
+
+// WithModeratorToken autentica as requisições como moderador da sala à qual o token pertence.
+func WithModeratorToken(token string) Option {
+	return func(c *Client) {
+		c.moderatorToken = token
+	}
+}
+
+// WithAPIKey autentica as requisições com a chave de API de uma sala, usada por bots para enviar mensagens.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// New cria um cliente para o servidor em baseURL, como "https://wsrs.example.com".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("client: unsupported scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Error é a resposta de erro do servidor.
+type Error struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // Preenchido quando o limite de requisições é atingido (429)
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("wsrs: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsStatus verifica se err é um *Error com o código de status informado.
+func IsStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// header adiciona os cabeçalhos de identificação do participante e do moderador.
+func (c *Client) header(h http.Header) {
+	if c.participantToken != "" {
+		h.Set("X-Participant-Token", c.participantToken)
+	}
+	if c.moderatorToken != "" {
+		h.Set("Authorization", "Bearer "+c.moderatorToken)
+	}
+	if c.apiKey != "" {
+		h.Set("X-Api-Key", c.apiKey)
+	}
+}
+
+// do envia uma requisição para a API v2 e decodifica a resposta JSON em out, se não for nil.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL.String()+"/api/v2"+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.header(req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp)
+	}
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// stream envia uma requisição GET para a API v2 e retorna o corpo da resposta sem decodificá-lo.
+func (c *Client) stream(ctx context.Context, path string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL.String()+"/api/v2"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.header(req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	return resp.Body, nil
+}
+
+// responseError monta o *Error de uma resposta com falha.
+func responseError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
@@ -0,0 +1,529 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joao-ressel/go-server/internal/api"
+	"github.com/joao-ressel/go-server/internal/config"
+	"github.com/joao-ressel/go-server/internal/store/pgstore/pgstoretest"
+	"github.com/joao-ressel/go-server/internal/webhook"
+)
+
+// testServer serve o api.Handler real sobre um banco em memória.
+// O handler pode ser trocado por restart, simulando um servidor que reinicia no mesmo endereço.
+type testServer struct {
+	*httptest.Server
+	store   *pgstoretest.Memory
+	opts    []api.Option
+	handler atomic.Pointer[api.Handler]
+}
+
+func newTestServer(t *testing.T, opts ...api.Option) *testServer {
+	s := &testServer{store: pgstoretest.NewMemory()}
+	s.opts = append([]api.Option{
+		api.WithDatabase(s.store),
+		api.WithParticipantKey([]byte("test participant key with enough bytes")),
+		api.WithRateLimit(config.RateLimitConfig{Default: config.RateLimit{Rate: 1000, Burst: 1000}}),
+	}, opts...)
+
+	handler := api.NewHandler(s.store, s.opts...)
+	s.handler.Store(&handler)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*s.handler.Load()).ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// restart encerra o handler atual, fechando as conexões WebSocket, e o substitui por um novo sobre o mesmo banco.
+// Até a troca, as novas inscrições são recusadas com 503, como durante o encerramento de um servidor.
+func (s *testServer) restart(t *testing.T) {
+	t.Helper()
+	if err := (*s.handler.Load()).Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	handler := api.NewHandler(s.store, s.opts...)
+	s.handler.Store(&handler)
+}
+
+func (s *testServer) client(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(s.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// newRoom cria uma sala e retorna o seu ID e um cliente autenticado como moderador.
+func (s *testServer) newRoom(t *testing.T, moderated bool) (uuid.UUID, *Client) {
+	t.Helper()
+	room, err := s.client(t).CreateRoom(context.Background(), CreateRoomParams{Theme: "Go", Moderated: moderated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return room.ID, s.client(t, WithModeratorToken(room.ModeratorToken))
+}
+
+func must[T any](t *testing.T) func(T, error) T {
+	return func(v T, err error) T {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nextEvent aguarda o próximo evento da inscrição.
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return Event{}
+}
+
+func TestClientRooms(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	roomID, mod := s.newRoom(t, false)
+	anon := s.client(t)
+
+	rooms := must[[]Room](t)(anon.GetRooms(ctx))
+	if len(rooms) != 1 || rooms[0].ID != roomID || rooms[0].Theme != "Go" || rooms[0].NowAnsweringID != nil {
+		t.Errorf("GetRooms = %+v", rooms)
+	}
+
+	room := must[Room](t)(anon.GetRoom(ctx, roomID))
+	if room.ID != roomID || room.Moderated {
+		t.Errorf("GetRoom = %+v", room)
+	}
+
+	moderated := true
+	updated := must[Room](t)(mod.UpdateRoomSettings(ctx, roomID, RoomSettings{Moderated: &moderated}))
+	if !updated.Moderated || updated.Version <= room.Version {
+		t.Errorf("UpdateRoomSettings = %+v, want moderated with a new version", updated)
+	}
+
+	messageID := must[CreateMessageResult](t)(s.client(t).CreateRoomMessage(ctx, roomID, "what about generics?")).ID
+	must[Message](t)(mod.ApproveMessage(ctx, roomID, messageID))
+
+	clone := must[CloneRoomResult](t)(mod.CloneRoom(ctx, roomID, CloneRoomParams{Theme: "Go, part 2", CarryOverUnanswered: true}))
+	if clone.ModeratorToken == "" || clone.MessagesCopied != 1 {
+		t.Errorf("CloneRoom = %+v, want 1 message copied", clone)
+	}
+	if cloned := must[Room](t)(anon.GetRoom(ctx, clone.ID)); cloned.Theme != "Go, part 2" || !cloned.Moderated {
+		t.Errorf("cloned room = %+v", cloned)
+	}
+
+	body := must[io.ReadCloser](t)(mod.ExportRoom(ctx, roomID, "json"))
+	export, err := io.ReadAll(body)
+	body.Close()
+	check(t, err)
+	if !bytes.Contains(export, []byte("what about generics?")) {
+		t.Errorf("export = %s, want the message", export)
+	}
+
+	imported := must[ImportRoomResult](t)(anon.ImportRoom(ctx, bytes.NewReader(export)))
+	if imported.ModeratorToken == "" || imported.MessagesImported != 1 {
+		t.Errorf("ImportRoom = %+v, want 1 message imported", imported)
+	}
+	if messages := must[[]Message](t)(anon.GetRoomMessages(ctx, imported.ID)); len(messages) != 1 || messages[0].Message != "what about generics?" {
+		t.Errorf("imported messages = %+v", messages)
+	}
+}
+
+func TestClientMessages(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	roomID, mod := s.newRoom(t, false)
+
+	participant := must[Participant](t)(s.client(t).CreateParticipant(ctx))
+	if participant.ParticipantID == uuid.Nil || participant.Token == "" {
+		t.Fatalf("CreateParticipant = %+v", participant)
+	}
+	audience := s.client(t, WithParticipantToken(participant.Token))
+
+	created := must[CreateMessageResult](t)(audience.CreateRoomMessage(ctx, roomID, "how does the scheduler work?"))
+	if created.ModerationStatus != "approved" {
+		t.Errorf("CreateRoomMessage = %+v, want approved", created)
+	}
+	id := created.ID
+
+	message := must[Message](t)(audience.GetRoomMessage(ctx, roomID, id))
+	if message.ID != id || message.RoomID != roomID || message.Status != "open" || message.CreatedAt.IsZero() || message.AnsweredAt != nil {
+		t.Errorf("GetRoomMessage = %+v", message)
+	}
+	if messages := must[[]Message](t)(audience.GetRoomMessages(ctx, roomID)); len(messages) != 1 || messages[0].ID != id {
+		t.Errorf("GetRoomMessages = %+v", messages)
+	}
+
+	if count := must[int64](t)(audience.ReactToMessage(ctx, roomID, id)); count != 1 {
+		t.Errorf("ReactToMessage = %d, want 1", count)
+	}
+	if count := must[int64](t)(audience.RemoveReactionFromMessage(ctx, roomID, id)); count != 0 {
+		t.Errorf("RemoveReactionFromMessage = %d, want 0", count)
+	}
+
+	if pinned := must[Message](t)(mod.PinMessage(ctx, roomID, id)); !pinned.Pinned {
+		t.Errorf("PinMessage = %+v, want pinned", pinned)
+	}
+	room := must[Room](t)(mod.SetNowAnswering(ctx, roomID, id))
+	if room.NowAnsweringID == nil || *room.NowAnsweringID != id {
+		t.Errorf("SetNowAnswering = %+v, want now answering %s", room, id)
+	}
+
+	details := must[RoomDetails](t)(audience.GetRoomDetails(ctx, roomID))
+	if details.ID != roomID || details.NowAnswering == nil || details.NowAnswering.ID != id || details.NowAnswering.Status != "answering" {
+		t.Errorf("GetRoomDetails now answering = %+v", details.NowAnswering)
+	}
+	if len(details.PinnedMessages) != 1 || details.PinnedMessages[0].ID != id {
+		t.Errorf("GetRoomDetails pinned = %+v", details.PinnedMessages)
+	}
+
+	if room := must[Room](t)(mod.ClearNowAnswering(ctx, roomID)); room.NowAnsweringID != nil {
+		t.Errorf("ClearNowAnswering = %+v, want no message", room)
+	}
+	if unpinned := must[Message](t)(mod.UnpinMessage(ctx, roomID, id)); unpinned.Pinned {
+		t.Errorf("UnpinMessage = %+v, want unpinned", unpinned)
+	}
+
+	if dismissed := must[Message](t)(mod.SetMessageStatus(ctx, roomID, id, "dismissed")); dismissed.Status != "dismissed" {
+		t.Errorf("SetMessageStatus = %+v, want dismissed", dismissed)
+	}
+	must[Message](t)(mod.SetMessageStatus(ctx, roomID, id, "open"))
+
+	check(t, mod.MarkMessageAsAnswered(ctx, roomID, id))
+	message = must[Message](t)(audience.GetRoomMessage(ctx, roomID, id))
+	if !message.Answered || message.Status != "answered" || message.AnsweredAt == nil {
+		t.Errorf("answered message = %+v", message)
+	}
+}
+
+func TestClientModeration(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	roomID, mod := s.newRoom(t, true)
+	participant := must[Participant](t)(s.client(t).CreateParticipant(ctx))
+	audience := s.client(t, WithParticipantToken(participant.Token))
+
+	pending := must[CreateMessageResult](t)(audience.CreateRoomMessage(ctx, roomID, "first"))
+	if pending.ModerationStatus != "pending" {
+		t.Fatalf("CreateRoomMessage = %+v, want pending", pending)
+	}
+	if approved := must[Message](t)(mod.ApproveMessage(ctx, roomID, pending.ID)); approved.ModerationStatus != "approved" {
+		t.Errorf("ApproveMessage = %+v", approved)
+	}
+	other := must[CreateMessageResult](t)(audience.CreateRoomMessage(ctx, roomID, "second"))
+	if rejected := must[Message](t)(mod.RejectMessage(ctx, roomID, other.ID)); rejected.ModerationStatus != "rejected" {
+		t.Errorf("RejectMessage = %+v", rejected)
+	}
+
+	check(t, audience.ReportMessage(ctx, roomID, pending.ID, "off topic"))
+	reports := must[[]ReportedMessage](t)(mod.GetRoomReports(ctx, roomID))
+	if len(reports) != 1 || reports[0].ID != pending.ID || reports[0].ReportCount != 1 {
+		t.Errorf("GetRoomReports = %+v", reports)
+	}
+
+	ban := must[Ban](t)(mod.CreateRoomBan(ctx, roomID, CreateBanParams{
+		ParticipantID:   participant.ParticipantID.String(),
+		Kind:            "mute",
+		Reason:          "spam",
+		DurationSeconds: 60,
+	}))
+	if ban.ParticipantID != participant.ParticipantID.String() || ban.Kind != "mute" || ban.ExpiresAt == nil || ban.CreatedAt.IsZero() {
+		t.Errorf("CreateRoomBan = %+v", ban)
+	}
+	if _, err := audience.CreateRoomMessage(ctx, roomID, "muted"); !IsStatus(err, http.StatusForbidden) {
+		t.Errorf("muted participant got %v, want 403", err)
+	}
+	if bans := must[[]Ban](t)(mod.GetRoomBans(ctx, roomID)); len(bans) != 1 || bans[0].ID != ban.ID {
+		t.Errorf("GetRoomBans = %+v", bans)
+	}
+	check(t, mod.DeleteRoomBan(ctx, roomID, ban.ID))
+	if bans := must[[]Ban](t)(mod.GetRoomBans(ctx, roomID)); len(bans) != 0 {
+		t.Errorf("GetRoomBans after delete = %+v", bans)
+	}
+
+	rule := must[FilterRule](t)(mod.CreateRoomFilterRule(ctx, roomID, CreateFilterRuleParams{Pattern: "spam", Action: "reject"}))
+	if rule.Pattern != "spam" || rule.IsRegex || rule.Action != "reject" {
+		t.Errorf("CreateRoomFilterRule = %+v", rule)
+	}
+	if _, err := audience.CreateRoomMessage(ctx, roomID, "buy spam"); err == nil {
+		t.Error("message matching a reject rule was accepted")
+	}
+	if rules := must[[]FilterRule](t)(mod.GetRoomFilterRules(ctx, roomID)); len(rules) != 1 || rules[0].ID != rule.ID {
+		t.Errorf("GetRoomFilterRules = %+v", rules)
+	}
+	check(t, mod.DeleteRoomFilterRule(ctx, roomID, rule.ID))
+	if rules := must[[]FilterRule](t)(mod.GetRoomFilterRules(ctx, roomID)); len(rules) != 0 {
+		t.Errorf("GetRoomFilterRules after delete = %+v", rules)
+	}
+}
+
+func TestClientAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	roomID, mod := s.newRoom(t, false)
+
+	created := must[CreateAPIKeyResult](t)(mod.CreateRoomAPIKey(ctx, roomID, "bot"))
+	if created.Key == "" || created.Name != "bot" || created.RoomID != roomID || created.LastUsedAt != nil {
+		t.Fatalf("CreateRoomAPIKey = %+v", created)
+	}
+
+	bot := s.client(t, WithAPIKey(created.Key))
+	id := must[CreateMessageResult](t)(bot.CreateAttributedRoomMessage(ctx, roomID, "from the stream", "")).ID
+	if message := must[Message](t)(bot.GetRoomMessage(ctx, roomID, id)); message.Attribution != "bot" {
+		t.Errorf("attribution = %q, want the key name", message.Attribution)
+	}
+
+	keys := must[[]APIKey](t)(mod.GetRoomAPIKeys(ctx, roomID))
+	if len(keys) != 1 || keys[0].ID != created.ID || keys[0].LastUsedAt == nil {
+		t.Errorf("GetRoomAPIKeys = %+v, want the used key", keys)
+	}
+
+	check(t, mod.DeleteRoomAPIKey(ctx, roomID, created.ID))
+	if _, err := bot.CreateAttributedRoomMessage(ctx, roomID, "revoked", ""); !IsStatus(err, http.StatusForbidden) {
+		t.Errorf("revoked key got %v, want 403", err)
+	}
+}
+
+func TestClientWebhooks(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	roomID, mod := s.newRoom(t, false)
+
+	hook := must[WebhookWithSecret](t)(mod.CreateRoomWebhook(ctx, roomID, CreateWebhookParams{
+		URL:        "https://hooks.example.com/wsrs",
+		EventKinds: []string{KindMessageReactionIncreased},
+	}))
+	if hook.Secret == "" || hook.URL != "https://hooks.example.com/wsrs" || hook.CreatedAt.IsZero() {
+		t.Fatalf("CreateRoomWebhook = %+v", hook)
+	}
+	if hooks := must[[]Webhook](t)(mod.GetRoomWebhooks(ctx, roomID)); len(hooks) != 1 || hooks[0].ID != hook.ID {
+		t.Errorf("GetRoomWebhooks = %+v", hooks)
+	}
+
+	messageID := must[CreateMessageResult](t)(s.client(t).CreateRoomMessage(ctx, roomID, "webhooks?")).ID
+	must[int64](t)(s.client(t).ReactToMessage(ctx, roomID, messageID))
+
+	deliveries := must[[]WebhookDelivery](t)(mod.GetRoomWebhookDeliveries(ctx, roomID, 10))
+	if len(deliveries) != 1 || deliveries[0].WebhookID != hook.ID || deliveries[0].Status != "pending" || deliveries[0].DeliveredAt != nil {
+		t.Fatalf("GetRoomWebhookDeliveries = %+v, want 1 pending delivery", deliveries)
+	}
+
+	var payload WebhookPayload
+	check(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	value, err := payload.DecodeValue()
+	check(t, err)
+	if reaction, ok := value.(MessageReactionIncreased); payload.RoomID != roomID || !ok || reaction.ID != messageID.String() || reaction.Count != 1 {
+		t.Errorf("payload = %+v with value %#v", payload, value)
+	}
+
+	// As assinaturas conferidas por VerifyWebhook são as produzidas pelo dispatcher
+	signed := func(timestamp time.Time, secret string) *http.Request {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(deliveries[0].Payload))
+		req.Header.Set(HeaderWebhookTimestamp, ts)
+		req.Header.Set(HeaderWebhookSignature, "sha256="+webhook.Sign(secret, ts, []byte(deliveries[0].Payload)))
+		return req
+	}
+	if body, err := VerifyWebhook(signed(time.Now(), hook.Secret), hook.Secret, time.Minute); err != nil || string(body) != deliveries[0].Payload {
+		t.Errorf("VerifyWebhook = %s, %v", body, err)
+	}
+	if _, err := VerifyWebhook(signed(time.Now(), "other"), hook.Secret, time.Minute); !errors.Is(err, ErrWebhookSignature) {
+		t.Errorf("VerifyWebhook with another secret = %v, want ErrWebhookSignature", err)
+	}
+	if _, err := VerifyWebhook(signed(time.Now().Add(-time.Hour), hook.Secret), hook.Secret, time.Minute); !errors.Is(err, ErrWebhookExpired) {
+		t.Errorf("VerifyWebhook with an old timestamp = %v, want ErrWebhookExpired", err)
+	}
+
+	check(t, mod.DeleteRoomWebhook(ctx, roomID, hook.ID))
+	if hooks := must[[]Webhook](t)(mod.GetRoomWebhooks(ctx, roomID)); len(hooks) != 0 {
+		t.Errorf("GetRoomWebhooks after delete = %+v", hooks)
+	}
+}
+
+func TestClientPoll(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	roomID, _ := s.newRoom(t, false)
+	c := s.client(t)
+
+	seq := must[uint64](t)(c.GetRoomSequence(ctx, roomID))
+	id := must[CreateMessageResult](t)(c.CreateRoomMessage(ctx, roomID, "polling")).ID
+
+	result := must[PollResult](t)(c.PollRoom(ctx, roomID, seq, time.Second))
+	if result.Seq <= seq || result.Truncated || len(result.Events) != 1 {
+		t.Fatalf("PollRoom = %+v, want 1 event", result)
+	}
+	event := result.Events[0]
+	if created, ok := event.Value.(MessageCreated); event.Version != 2 || event.Kind != KindMessageCreated || !ok || created.ID != id.String() {
+		t.Errorf("event = %+v", event)
+	}
+
+	if result := must[PollResult](t)(c.PollRoom(ctx, roomID, result.Seq, 10*time.Millisecond)); len(result.Events) != 0 {
+		t.Errorf("PollRoom without new events = %+v", result)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t, api.WithRateLimit(config.RateLimitConfig{
+		Default: config.RateLimit{Rate: 1000, Burst: 1000},
+		Routes:  map[string]config.RateLimit{"POST /api/rooms": {Rate: 0.5, Burst: 1}},
+	}))
+	roomID, _ := s.newRoom(t, false)
+	c := s.client(t)
+
+	_, err := c.GetRoom(ctx, uuid.New())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "room not found" || apiErr.RetryAfter != 0 {
+		t.Errorf("GetRoom of an unknown room = %#v, want 400 room not found", err)
+	}
+	if !IsStatus(err, http.StatusBadRequest) || IsStatus(err, http.StatusNotFound) {
+		t.Errorf("IsStatus(%v) does not match its status code", err)
+	}
+
+	if _, err := c.GetRoomBans(ctx, roomID); !IsStatus(err, http.StatusUnauthorized) {
+		t.Errorf("GetRoomBans without a token = %v, want 401", err)
+	}
+	if _, err := s.client(t, WithModeratorToken("wrong")).GetRoomBans(ctx, roomID); !IsStatus(err, http.StatusForbidden) {
+		t.Errorf("GetRoomBans with a wrong token = %v, want 403", err)
+	}
+
+	// O limite da rota foi consumido por newRoom
+	_, err = c.CreateRoom(ctx, CreateRoomParams{Theme: "again"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter != 2*time.Second {
+		t.Errorf("CreateRoom over the limit = %#v, want 429 with Retry-After", err)
+	}
+
+	if IsStatus(context.Canceled, http.StatusBadRequest) {
+		t.Error("IsStatus matched an error that is not an *Error")
+	}
+	if _, err := New("ws://localhost"); err == nil {
+		t.Error("New accepted a websocket URL")
+	}
+}
+
+func TestSubscribeReconnects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := newTestServer(t)
+	roomID, mod := s.newRoom(t, false)
+
+	sub := s.client(t, WithReconnectBackoff(Backoff{Min: 10 * time.Millisecond, Max: 50 * time.Millisecond}))
+	events, err := sub.Subscribe(ctx, roomID)
+	check(t, err)
+
+	id := must[CreateMessageResult](t)(mod.CreateRoomMessage(ctx, roomID, "before")).ID
+	if event := nextEvent(t, events); event.Kind != KindMessageCreated || event.Value.(MessageCreated).ID != id.String() {
+		t.Fatalf("event = %+v, want message_created", event)
+	}
+
+	s.restart(t)
+	if event := nextEvent(t, events); event.Kind != KindServerShuttingDown || event.Err != nil {
+		t.Fatalf("event = %+v, want server_shutting_down", event)
+	}
+
+	// Os eventos enviados antes da reconexão são perdidos; as mensagens são enviadas até que uma seja recebida
+	tick := time.NewTicker(20 * time.Millisecond)
+	defer tick.Stop()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Kind != KindMessageCreated || event.Value.(MessageCreated).Message != "after" {
+				t.Fatalf("event = %+v, want message_created after reconnecting", event)
+			}
+			cancel()
+			for range events { // O canal é fechado com o cancelamento
+			}
+			return
+		case <-tick.C:
+			must[CreateMessageResult](t)(mod.CreateRoomMessage(ctx, roomID, "after"))
+		case <-deadline:
+			t.Fatal("timed out waiting for the subscription to reconnect")
+		}
+	}
+}
+
+func TestSubscribeStopsWhenRefused(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	roomID, mod := s.newRoom(t, false)
+
+	participant := must[Participant](t)(s.client(t).CreateParticipant(ctx))
+	sub := s.client(t,
+		WithParticipantToken(participant.Token),
+		WithReconnectBackoff(Backoff{Min: time.Millisecond, Max: time.Millisecond}),
+	)
+
+	if _, err := sub.Subscribe(ctx, uuid.New()); !IsStatus(err, http.StatusBadRequest) {
+		t.Errorf("Subscribe to an unknown room = %v, want 400", err)
+	}
+
+	events, err := sub.Subscribe(ctx, roomID)
+	check(t, err)
+
+	// O banimento encerra a inscrição, e a reconexão é recusada de forma definitiva
+	must[Ban](t)(mod.CreateRoomBan(ctx, roomID, CreateBanParams{ParticipantID: participant.ParticipantID.String(), Kind: "ban"}))
+
+	event := nextEvent(t, events)
+	if event.Kind != "" || !IsStatus(event.Err, http.StatusForbidden) {
+		t.Fatalf("event = %+v, want a 403 error", event)
+	}
+	select {
+	case event, ok := <-events:
+		if ok {
+			t.Errorf("got %+v after the error, want the channel closed", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("channel not closed after the error")
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Min: 100 * time.Millisecond, Max: time.Second}
+	for attempt, want := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		for range 50 {
+			// A variação aleatória fica entre a metade e o total do intervalo
+			if d := b.delay(attempt); d < want/2 || d > want {
+				t.Fatalf("delay(%d) = %v, want between %v and %v", attempt, d, want/2, want)
+			}
+		}
+	}
+}
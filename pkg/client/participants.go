@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Participant é uma identidade de participante emitida pelo servidor.
+// O ID é o usado pelos moderadores nos banimentos; o token é enviado nas requisições (veja WithParticipantToken).
+type Participant struct {
+	ParticipantID uuid.UUID `json:"participant_id"`
+	Token         string    `json:"token"`
+}
+
+// CreateParticipant emite uma nova identidade de participante.
+func (c *Client) CreateParticipant(ctx context.Context) (Participant, error) {
+	var participant Participant
+	err := c.do(ctx, http.MethodPost, "/participants", nil, &participant)
+	return participant, err
+}
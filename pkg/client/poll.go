@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PollResult é a resposta de uma consulta de long polling.
+type PollResult struct {
+	Seq       uint64  // Sequência do último evento da sala, para a consulta seguinte
+	Events    []Event // Eventos posteriores à sequência consultada, do mais antigo ao mais recente
+	Truncated bool    // Eventos foram perdidos; a sala e as mensagens devem ser recarregadas
+}
+
+// GetRoomSequence obtém a sequência atual dos eventos da sala, usada na primeira chamada de PollRoom.
+// Para não perder eventos, obtenha a sequência antes de carregar as mensagens da sala.
+func (c *Client) GetRoomSequence(ctx context.Context, roomID uuid.UUID) (uint64, error) {
+	result, err := c.poll(ctx, roomID, nil)
+	return result.Seq, err
+}
+
+// PollRoom aguarda eventos da sala posteriores a since, por até timeout (no máximo 50s, limitado pelo servidor).
+// É uma alternativa a Subscribe para ambientes sem WebSocket; sem eventos, Events vem vazio ao fim do prazo.
+func (c *Client) PollRoom(ctx context.Context, roomID uuid.UUID, since uint64, timeout time.Duration) (PollResult, error) {
+	return c.poll(ctx, roomID, url.Values{
+		"since":   {strconv.FormatUint(since, 10)},
+		"timeout": {timeout.String()},
+	})
+}
+
+func (c *Client) poll(ctx context.Context, roomID uuid.UUID, query url.Values) (PollResult, error) {
+	var response struct {
+		Seq    uint64 `json:"seq"`
+		Events []struct {
+			Version int             `json:"version"`
+			Kind    string          `json:"kind"`
+			Value   json.RawMessage `json:"value"`
+		} `json:"events"`
+		Truncated bool `json:"truncated"`
+	}
+	path := roomPath(roomID) + "/poll"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return PollResult{}, err
+	}
+
+	result := PollResult{Seq: response.Seq, Truncated: response.Truncated, Events: make([]Event, 0, len(response.Events))}
+	for _, envelope := range response.Events {
+		event := Event{Version: envelope.Version, Kind: envelope.Kind, Raw: envelope.Value}
+		event.Value, event.Err = decodeEventValue(envelope.Kind, envelope.Value)
+		result.Events = append(result.Events, event)
+	}
+	return result, nil
+}
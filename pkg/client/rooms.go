@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+)
+
+// CreateRoomParams são os dados de uma sala nova.
+type CreateRoomParams struct {
+	Theme     string `json:"theme"`
+	Moderated bool   `json:"moderated"`
+}
+
+// CreateRoomResult é a resposta da criação de uma sala.
+// O token de moderador só é devolvido nesta resposta.
+type CreateRoomResult struct {
+	ID             uuid.UUID `json:"id"`
+	ModeratorToken string    `json:"moderator_token"`
+}
+
+// CreateRoom cria uma sala.
+func (c *Client) CreateRoom(ctx context.Context, params CreateRoomParams) (CreateRoomResult, error) {
+	var result CreateRoomResult
+	err := c.do(ctx, http.MethodPost, "/rooms", params, &result)
+	return result, err
+}
+
+// GetRooms lista as salas.
+func (c *Client) GetRooms(ctx context.Context) ([]Room, error) {
+	var rooms []Room
+	err := c.do(ctx, http.MethodGet, "/rooms", nil, &rooms)
+	return rooms, err
+}
+
+// GetRoom obtém uma sala.
+func (c *Client) GetRoom(ctx context.Context, roomID uuid.UUID) (Room, error) {
+	var room Room
+	err := c.do(ctx, http.MethodGet, roomPath(roomID), nil, &room)
+	return room, err
+}
+
+// RoomDetails é uma sala com as suas mensagens em destaque, como retornada por GetRoomDetails.
+type RoomDetails struct {
+	Room
+	NowAnswering   *Message  `json:"now_answering"`   // Mensagem sendo respondida, se houver
+	PinnedMessages []Message `json:"pinned_messages"` // Mensagens fixadas, da mais antiga para a mais recente
+}
+
+// GetRoomDetails obtém uma sala com a mensagem sendo respondida e as mensagens fixadas.
+func (c *Client) GetRoomDetails(ctx context.Context, roomID uuid.UUID) (RoomDetails, error) {
+	var room RoomDetails
+	err := c.do(ctx, http.MethodGet, roomPath(roomID), nil, &room)
+	return room, err
+}
+
+// SetNowAnswering define a mensagem sendo respondida na sala, exibida em destaque para a audiência (moderador).
+func (c *Client) SetNowAnswering(ctx context.Context, roomID, messageID uuid.UUID) (Room, error) {
+	var room Room
+	err := c.do(ctx, http.MethodPut, roomPath(roomID)+"/now-answering", map[string]uuid.UUID{"message_id": messageID}, &room)
+	return room, err
+}
+
+// ClearNowAnswering remove o destaque da mensagem sendo respondida (moderador).
+func (c *Client) ClearNowAnswering(ctx context.Context, roomID uuid.UUID) (Room, error) {
+	var room Room
+	err := c.do(ctx, http.MethodDelete, roomPath(roomID)+"/now-answering", nil, &room)
+	return room, err
+}
+
+// ExportRoom exporta a sala e as suas mensagens no formato informado ("json", "csv" ou "md").
+// O chamador deve fechar o io.ReadCloser retornado.
+func (c *Client) ExportRoom(ctx context.Context, roomID uuid.UUID, format string) (io.ReadCloser, error) {
+	return c.stream(ctx, roomPath(roomID)+"/export?format="+url.QueryEscape(format))
+}
+
+// ImportRoom cria uma sala a partir de uma exportação em JSON, como a retornada por ExportRoom com o formato "json".
+func (c *Client) ImportRoom(ctx context.Context, export io.Reader) (ImportRoomResult, error) {
+	var result ImportRoomResult
+	data, err := io.ReadAll(export)
+	if err != nil {
+		return result, err
+	}
+	err = c.do(ctx, http.MethodPost, "/rooms/import", json.RawMessage(data), &result)
+	return result, err
+}
+
+// ImportRoomResult é a resposta da importação de uma sala.
+type ImportRoomResult struct {
+	ID               uuid.UUID `json:"id"`
+	ModeratorToken   string    `json:"moderator_token"`
+	MessagesImported int       `json:"messages_imported"`
+}
+
+// CloneRoomParams são as opções da clonagem de uma sala.
+type CloneRoomParams struct {
+	Theme               string `json:"theme,omitempty"` // Vazio mantém o tema da sala de origem
+	CarryOverUnanswered bool   `json:"carry_over_unanswered"`
+}
+
+// CloneRoomResult é a resposta da clonagem de uma sala.
+type CloneRoomResult struct {
+	ID             uuid.UUID `json:"id"`
+	ModeratorToken string    `json:"moderator_token"`
+	MessagesCopied int64     `json:"messages_copied"`
+}
+
+// CloneRoom cria uma sala com as mesmas configurações de outra (moderador).
+func (c *Client) CloneRoom(ctx context.Context, roomID uuid.UUID, params CloneRoomParams) (CloneRoomResult, error) {
+	var result CloneRoomResult
+	err := c.do(ctx, http.MethodPost, roomPath(roomID)+"/clone", params, &result)
+	return result, err
+}
+
+// RoomSettings são as configurações alteráveis de uma sala; campos nil não são alterados.
+type RoomSettings struct {
+	Moderated *bool `json:"moderated,omitempty"`
+}
+
+// UpdateRoomSettings altera as configurações de uma sala (moderador).
+func (c *Client) UpdateRoomSettings(ctx context.Context, roomID uuid.UUID, settings RoomSettings) (Room, error) {
+	var room Room
+	err := c.do(ctx, http.MethodPatch, roomPath(roomID)+"/settings", settings, &room)
+	return room, err
+}
+
+// GetRoomReports lista as mensagens denunciadas de uma sala (moderador).
+func (c *Client) GetRoomReports(ctx context.Context, roomID uuid.UUID) ([]ReportedMessage, error) {
+	var reports []ReportedMessage
+	err := c.do(ctx, http.MethodGet, roomPath(roomID)+"/reports", nil, &reports)
+	return reports, err
+}
+
+// GetRoomBans lista os banimentos e silenciamentos de uma sala (moderador).
+func (c *Client) GetRoomBans(ctx context.Context, roomID uuid.UUID) ([]Ban, error) {
+	var bans []Ban
+	err := c.do(ctx, http.MethodGet, roomPath(roomID)+"/bans", nil, &bans)
+	return bans, err
+}
+
+// CreateBanParams descreve uma restrição nova; ParticipantID ou IPRange deve ser informado.
+type CreateBanParams struct {
+	ParticipantID   string `json:"participant_id,omitempty"`
+	IPRange         string `json:"ip_range,omitempty"` // Endereço IP ou faixa CIDR
+	Kind            string `json:"kind"`               // "ban" ou "mute"
+	Reason          string `json:"reason,omitempty"`
+	DurationSeconds int64  `json:"duration_seconds,omitempty"` // Zero indica uma restrição sem prazo
+}
+
+// CreateRoomBan bane ou silencia um participante ou uma faixa de IPs (moderador).
+func (c *Client) CreateRoomBan(ctx context.Context, roomID uuid.UUID, params CreateBanParams) (Ban, error) {
+	var ban Ban
+	err := c.do(ctx, http.MethodPost, roomPath(roomID)+"/bans", params, &ban)
+	return ban, err
+}
+
+// DeleteRoomBan remove uma restrição (moderador).
+func (c *Client) DeleteRoomBan(ctx context.Context, roomID, banID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, roomPath(roomID)+"/bans/"+banID.String(), nil, nil)
+}
+
+// GetRoomFilterRules lista as regras do filtro de conteúdo de uma sala (moderador).
+func (c *Client) GetRoomFilterRules(ctx context.Context, roomID uuid.UUID) ([]FilterRule, error) {
+	var rules []FilterRule
+	err := c.do(ctx, http.MethodGet, roomPath(roomID)+"/filters", nil, &rules)
+	return rules, err
+}
+
+// CreateFilterRuleParams descreve uma regra nova do filtro de conteúdo.
+type CreateFilterRuleParams struct {
+	Pattern string `json:"pattern"`
+	Regex   bool   `json:"regex"`
+	Action  string `json:"action"` // "allow", "mask", "moderate" ou "reject"
+}
+
+// CreateRoomFilterRule adiciona uma regra ao filtro de conteúdo de uma sala (moderador).
+func (c *Client) CreateRoomFilterRule(ctx context.Context, roomID uuid.UUID, params CreateFilterRuleParams) (FilterRule, error) {
+	var rule FilterRule
+	err := c.do(ctx, http.MethodPost, roomPath(roomID)+"/filters", params, &rule)
+	return rule, err
+}
+
+// DeleteRoomFilterRule remove uma regra do filtro de conteúdo (moderador).
+func (c *Client) DeleteRoomFilterRule(ctx context.Context, roomID, ruleID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, roomPath(roomID)+"/filters/"+ruleID.String(), nil, nil)
+}
+
+// CreateAPIKeyResult é a resposta da criação de uma chave de API.
+// A chave só é devolvida nesta resposta.
+type CreateAPIKeyResult struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// CreateRoomAPIKey cria uma chave de API para que um bot envie mensagens à sala (moderador).
+// name identifica o bot e é a atribuição padrão das mensagens enviadas com a chave.
+func (c *Client) CreateRoomAPIKey(ctx context.Context, roomID uuid.UUID, name string) (CreateAPIKeyResult, error) {
+	var result CreateAPIKeyResult
+	err := c.do(ctx, http.MethodPost, roomPath(roomID)+"/api-keys", map[string]string{"name": name}, &result)
+	return result, err
+}
+
+// GetRoomAPIKeys lista as chaves de API da sala, sem as chaves em si (moderador).
+func (c *Client) GetRoomAPIKeys(ctx context.Context, roomID uuid.UUID) ([]APIKey, error) {
+	var keys []APIKey
+	err := c.do(ctx, http.MethodGet, roomPath(roomID)+"/api-keys", nil, &keys)
+	return keys, err
+}
+
+// DeleteRoomAPIKey revoga uma chave de API (moderador).
+func (c *Client) DeleteRoomAPIKey(ctx context.Context, roomID, keyID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, roomPath(roomID)+"/api-keys/"+keyID.String(), nil, nil)
+}
+
+// CreateMessageResult é a resposta da criação de uma mensagem.
+type CreateMessageResult struct {
+	ID               uuid.UUID `json:"id"`
+	ModerationStatus string    `json:"moderation_status"` // "pending" se a mensagem aguarda moderação
+}
+
+// CreateRoomMessage envia uma mensagem para a sala.
+func (c *Client) CreateRoomMessage(ctx context.Context, roomID uuid.UUID, message string) (CreateMessageResult, error) {
+	var result CreateMessageResult
+	err := c.do(ctx, http.MethodPost, roomPath(roomID)+"/messages", map[string]string{"message": message}, &result)
+	return result, err
+}
+
+// CreateAttributedRoomMessage envia uma mensagem atribuída a um bot ou a uma origem.
+// Exige um cliente criado com WithAPIKey; attribution vazia usa o nome da chave.
+func (c *Client) CreateAttributedRoomMessage(ctx context.Context, roomID uuid.UUID, message, attribution string) (CreateMessageResult, error) {
+	var result CreateMessageResult
+	body := map[string]string{"message": message, "attribution": attribution}
+	err := c.do(ctx, http.MethodPost, roomPath(roomID)+"/messages", body, &result)
+	return result, err
+}
+
+// GetRoomMessages lista as mensagens da sala; apenas moderadores recebem as mensagens não aprovadas.
+func (c *Client) GetRoomMessages(ctx context.Context, roomID uuid.UUID) ([]Message, error) {
+	var messages []Message
+	err := c.do(ctx, http.MethodGet, roomPath(roomID)+"/messages", nil, &messages)
+	return messages, err
+}
+
+// GetRoomMessage obtém uma mensagem da sala.
+func (c *Client) GetRoomMessage(ctx context.Context, roomID, messageID uuid.UUID) (Message, error) {
+	var message Message
+	err := c.do(ctx, http.MethodGet, messagePath(roomID, messageID), nil, &message)
+	return message, err
+}
+
+// ReactToMessage reage a uma mensagem e retorna a contagem atualizada de reações.
+func (c *Client) ReactToMessage(ctx context.Context, roomID, messageID uuid.UUID) (int64, error) {
+	return c.react(ctx, http.MethodPatch, roomID, messageID)
+}
+
+// RemoveReactionFromMessage remove uma reação de uma mensagem e retorna a contagem atualizada de reações.
+func (c *Client) RemoveReactionFromMessage(ctx context.Context, roomID, messageID uuid.UUID) (int64, error) {
+	return c.react(ctx, http.MethodDelete, roomID, messageID)
+}
+
+func (c *Client) react(ctx context.Context, method string, roomID, messageID uuid.UUID) (int64, error) {
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	err := c.do(ctx, method, messagePath(roomID, messageID)+"/react", nil, &result)
+	return result.Count, err
+}
+
+// MarkMessageAsAnswered marca uma mensagem como respondida (moderador).
+func (c *Client) MarkMessageAsAnswered(ctx context.Context, roomID, messageID uuid.UUID) error {
+	return c.do(ctx, http.MethodPatch, messagePath(roomID, messageID)+"/answer", nil, nil)
+}
+
+// SetMessageStatus altera o estado de resposta de uma mensagem (moderador):
+// "open", "answering", "answered", "dismissed" ou "duplicate". Toda alteração pode ser desfeita com "open".
+func (c *Client) SetMessageStatus(ctx context.Context, roomID, messageID uuid.UUID, status string) (Message, error) {
+	var message Message
+	err := c.do(ctx, http.MethodPatch, messagePath(roomID, messageID)+"/status", map[string]string{"status": status}, &message)
+	return message, err
+}
+
+// ApproveMessage aprova uma mensagem pendente (moderador).
+func (c *Client) ApproveMessage(ctx context.Context, roomID, messageID uuid.UUID) (Message, error) {
+	var message Message
+	err := c.do(ctx, http.MethodPatch, messagePath(roomID, messageID)+"/approve", nil, &message)
+	return message, err
+}
+
+// RejectMessage rejeita uma mensagem pendente (moderador).
+func (c *Client) RejectMessage(ctx context.Context, roomID, messageID uuid.UUID) (Message, error) {
+	var message Message
+	err := c.do(ctx, http.MethodPatch, messagePath(roomID, messageID)+"/reject", nil, &message)
+	return message, err
+}
+
+// PinMessage fixa uma mensagem aprovada no topo da sala (moderador).
+func (c *Client) PinMessage(ctx context.Context, roomID, messageID uuid.UUID) (Message, error) {
+	var message Message
+	err := c.do(ctx, http.MethodPatch, messagePath(roomID, messageID)+"/pin", nil, &message)
+	return message, err
+}
+
+// UnpinMessage desafixa uma mensagem (moderador).
+func (c *Client) UnpinMessage(ctx context.Context, roomID, messageID uuid.UUID) (Message, error) {
+	var message Message
+	err := c.do(ctx, http.MethodDelete, messagePath(roomID, messageID)+"/pin", nil, &message)
+	return message, err
+}
+
+// ReportMessage denuncia uma mensagem.
+func (c *Client) ReportMessage(ctx context.Context, roomID, messageID uuid.UUID, reason string) error {
+	return c.do(ctx, http.MethodPost, messagePath(roomID, messageID)+"/report", map[string]string{"reason": reason}, nil)
+}
+
+func roomPath(roomID uuid.UUID) string {
+	return "/rooms/" + roomID.String()
+}
+
+func messagePath(roomID, messageID uuid.UUID) string {
+	return roomPath(roomID) + "/messages/" + messageID.String()
+}
@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// apiVersion é a versão da API e do envelope dos eventos usada pelo cliente.
+const apiVersion = 2
+
+// Tipos dos eventos recebidos pelo WebSocket
+const (
+	KindMessageCreated           = "message_created"
+	KindMessageReactionIncreased = "message_reaction_increased"
+	KindMessageReactionDecreased = "message_reaction_decreased"
+	KindMessageAnswered          = "message_answered" // Apenas para a v1; o cliente recebe KindMessageStatusChanged
+	KindMessageStatusChanged     = "message_status_changed"
+	KindMessagePending           = "message_pending"  // Enviado apenas aos moderadores
+	KindMessageRejected          = "message_rejected" // Enviado apenas aos moderadores
+	KindMessageHidden            = "message_hidden"
+	KindMessagePinned            = "message_pinned"
+	KindNowAnswering             = "now_answering"
+	KindServerShuttingDown       = "server_shutting_down"
+)
+
+// Valores dos eventos, conforme o tipo
+type (
+	MessageCreated struct {
+		ID          string `json:"id"`
+		Message     string `json:"message"`
+		Attribution string `json:"attribution,omitempty"` // Bot ou origem da mensagem, quando enviada com uma chave de API
+	}
+
+	MessageReactionIncreased struct {
+		ID    string `json:"id"`
+		Count int64  `json:"count"`
+	}
+
+	MessageReactionDecreased struct {
+		ID    string `json:"id"`
+		Count int64  `json:"count"`
+	}
+
+	MessageAnswered struct {
+		ID string `json:"id"`
+	}
+
+	MessageStatusChanged struct {
+		ID        string `json:"id"`
+		OldStatus string `json:"old_status"`
+		NewStatus string `json:"new_status"`
+	}
+
+	MessagePending struct {
+		ID          string `json:"id"`
+		Message     string `json:"message"`
+		Attribution string `json:"attribution,omitempty"`
+	}
+
+	MessageRejected struct {
+		ID string `json:"id"`
+	}
+
+	MessageHidden struct {
+		ID string `json:"id"`
+	}
+
+	MessagePinned struct {
+		ID     string `json:"id"`
+		Pinned bool   `json:"pinned"` // Falso quando a mensagem foi desafixada
+	}
+
+	NowAnswering struct {
+		ID      string `json:"id"`      // Vazio quando nenhuma mensagem está sendo respondida
+		Message string `json:"message"` // Texto da mensagem, para que ela seja exibida sem outra consulta
+	}
+
+	ServerShuttingDown struct {
+		Reason string `json:"reason"`
+	}
+)
+
+// Event é um evento recebido de uma sala.
+// Value contém o valor decodificado no tipo correspondente a Kind (por exemplo, MessageCreated para
+// KindMessageCreated); para tipos desconhecidos, Value é nil e o valor original fica em Raw.
+// Err é preenchido quando o valor não pode ser decodificado e no último evento de uma inscrição encerrada por erro.
+type Event struct {
+	Version int
+	Kind    string
+	Value   any
+	Raw     json.RawMessage
+	Err     error
+}
+
+// Backoff controla o intervalo entre as tentativas de reconexão, que dobra a cada falha até Max.
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// DefaultBackoff é o intervalo de reconexão usado quando nenhum outro é configurado.
+var DefaultBackoff = Backoff{Min: 500 * time.Millisecond, Max: 30 * time.Second}
+
+// WithReconnectBackoff altera o intervalo entre as tentativas de reconexão de Subscribe.
+func WithReconnectBackoff(b Backoff) Option {
+	return func(c *Client) {
+		c.backoff = b
+	}
+}
+
+// delay retorna a espera antes da tentativa seguinte, com variação aleatória para espalhar as reconexões.
+func (b Backoff) delay(attempt int) time.Duration {
+	d := b.Min
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	d = min(d, b.Max)
+	return d/2 + rand.N(d/2+1)
+}
+
+// Subscribe se inscreve nos eventos de uma sala e os entrega no canal retornado.
+//
+// A primeira conexão é feita antes do retorno, para que erros como sala inexistente ou participante banido
+// sejam informados imediatamente. Depois disso, a conexão é refeita automaticamente com backoff sempre que cair,
+// inclusive quando o servidor encerra; eventos enviados enquanto o cliente estava desconectado são perdidos.
+// O canal é fechado quando ctx é cancelado ou quando o servidor recusa a reconexão.
+func (c *Client) Subscribe(ctx context.Context, roomID uuid.UUID) (<-chan Event, error) {
+	conn, err := c.dial(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		for attempt := 0; ; {
+			if conn != nil {
+				attempt = 0
+				c.read(ctx, conn, events)
+				conn = nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.backoff.delay(attempt)):
+			}
+			attempt++
+
+			conn, err = c.dial(ctx, roomID)
+			if err != nil && permanent(err) {
+				select {
+				case events <- Event{Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// dial abre a conexão WebSocket da sala, negociando a versão 2 do envelope.
+func (c *Client) dial(ctx context.Context, roomID uuid.UUID) (*websocket.Conn, error) {
+	u := *c.baseURL
+	u.Scheme = map[string]string{"http": "ws", "https": "wss"}[u.Scheme]
+	u.Path += "/subscribe/" + roomID.String()
+	u.RawQuery = "version=" + strconv.Itoa(apiVersion)
+
+	header := http.Header{}
+	c.header(header)
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+		Jar:              c.httpClient.Jar,
+	}
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = t.TLSClientConfig
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			return nil, responseError(resp)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// read entrega os eventos da conexão até que ela caia ou ctx seja cancelado.
+func (c *Client) read(ctx context.Context, conn *websocket.Conn, events chan<- Event) {
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	for {
+		var envelope struct {
+			Version int             `json:"version"`
+			Kind    string          `json:"kind"`
+			Value   json.RawMessage `json:"value"`
+		}
+		if err := conn.ReadJSON(&envelope); err != nil {
+			return
+		}
+
+		event := Event{Version: envelope.Version, Kind: envelope.Kind, Raw: envelope.Value}
+		event.Value, event.Err = decodeEventValue(envelope.Kind, envelope.Value)
+
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// decodeEventValue decodifica o valor de um evento no tipo correspondente ao seu tipo.
+func decodeEventValue(kind string, raw json.RawMessage) (any, error) {
+	switch kind {
+	case KindMessageCreated:
+		return decode[MessageCreated](raw)
+	case KindMessageReactionIncreased:
+		return decode[MessageReactionIncreased](raw)
+	case KindMessageReactionDecreased:
+		return decode[MessageReactionDecreased](raw)
+	case KindMessageAnswered:
+		return decode[MessageAnswered](raw)
+	case KindMessageStatusChanged:
+		return decode[MessageStatusChanged](raw)
+	case KindMessagePending:
+		return decode[MessagePending](raw)
+	case KindMessageRejected:
+		return decode[MessageRejected](raw)
+	case KindMessageHidden:
+		return decode[MessageHidden](raw)
+	case KindMessagePinned:
+		return decode[MessagePinned](raw)
+	case KindNowAnswering:
+		return decode[NowAnswering](raw)
+	case KindServerShuttingDown:
+		return decode[ServerShuttingDown](raw)
+	default:
+		return nil, nil
+	}
+}
+
+func decode[T any](raw json.RawMessage) (any, error) {
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// permanent indica se o servidor recusou a conexão de forma definitiva (sala inexistente, banimento, origem recusada).
+// Limites de requisições e indisponibilidade temporária continuam sendo tentados.
+func permanent(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode < http.StatusInternalServerError && apiErr.StatusCode != http.StatusTooManyRequests
+}
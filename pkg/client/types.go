@@ -0,0 +1,94 @@
+package client
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Room é uma sala, no formato da versão 2 da API.
+type Room struct {
+	ID             uuid.UUID  `json:"id"`
+	Theme          string     `json:"theme"`
+	Moderated      bool       `json:"moderated"`
+	Version        int64      `json:"version"`          // Muda a cada alteração da sala ou das suas mensagens
+	NowAnsweringID *uuid.UUID `json:"now_answering_id"` // Mensagem sendo respondida, se houver
+}
+
+// Message é uma mensagem de uma sala.
+type Message struct {
+	ID               uuid.UUID  `json:"id"`
+	RoomID           uuid.UUID  `json:"room_id"`
+	Message          string     `json:"message"`
+	ReactionCount    int64      `json:"reaction_count"`
+	Answered         bool       `json:"answered"`          // Verdadeiro quando Status é "answered"
+	ModerationStatus string     `json:"moderation_status"` // "approved", "pending" ou "rejected"
+	Attribution      string     `json:"attribution"`       // Bot ou origem da mensagem, quando enviada com uma chave de API
+	CreatedAt        time.Time  `json:"created_at"`
+	AnsweredAt       *time.Time `json:"answered_at"`
+	Version          int64      `json:"version"`
+	Pinned           bool       `json:"pinned"`
+	Status           string     `json:"status"` // "open", "answering", "answered", "dismissed" ou "duplicate"
+}
+
+// Ban é um banimento ou silenciamento de um participante ou de uma faixa de IPs.
+type Ban struct {
+	ID            uuid.UUID  `json:"id"`
+	RoomID        uuid.UUID  `json:"room_id"`
+	ParticipantID string     `json:"participant_id"`
+	IPRange       string     `json:"ip_range"`
+	Kind          string     `json:"kind"` // "ban" ou "mute"
+	Reason        string     `json:"reason"`
+	ExpiresAt     *time.Time `json:"expires_at"` // Nil para uma restrição sem prazo
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// FilterRule é uma regra do filtro de conteúdo de uma sala.
+type FilterRule struct {
+	ID      uuid.UUID `json:"id"`
+	RoomID  uuid.UUID `json:"room_id"`
+	Pattern string    `json:"pattern"`
+	IsRegex bool      `json:"is_regex"`
+	Action  string    `json:"action"` // "allow", "mask", "moderate" ou "reject"
+}
+
+// ReportedMessage é uma mensagem denunciada, com a quantidade de denúncias ainda não revisadas.
+type ReportedMessage struct {
+	ID               uuid.UUID `json:"id"`
+	Message          string    `json:"message"`
+	ModerationStatus string    `json:"moderation_status"`
+	ReportCount      int64     `json:"report_count"`
+}
+
+// APIKey é uma chave de API de uma sala, sem a chave em si.
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	RoomID     uuid.UUID  `json:"room_id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"` // Nil se a chave nunca foi usada
+}
+
+// Webhook é um webhook de uma sala, sem o segredo das assinaturas.
+type Webhook struct {
+	ID         uuid.UUID `json:"id"`
+	RoomID     uuid.UUID `json:"room_id"`
+	URL        string    `json:"url"`
+	EventKinds []string  `json:"event_kinds"` // Vazio assina todos os tipos de evento
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery é uma entrega de um evento a um webhook.
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id"`
+	WebhookID      uuid.UUID  `json:"webhook_id"`
+	EventKind      string     `json:"event_kind"`
+	Payload        string     `json:"payload"` // Corpo enviado, um WebhookPayload em JSON
+	Status         string     `json:"status"`  // "pending", "delivered" ou "failed"
+	Attempts       int32      `json:"attempts"`
+	LastStatusCode int32      `json:"last_status_code"`
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cabeçalhos das entregas de webhooks
+const (
+	HeaderWebhookEvent     = "X-Wsrs-Event"     // Tipo do evento
+	HeaderWebhookDelivery  = "X-Wsrs-Delivery"  // ID da entrega, repetido nas novas tentativas
+	HeaderWebhookTimestamp = "X-Wsrs-Timestamp" // Momento do envio, em segundos desde a época Unix
+	HeaderWebhookSignature = "X-Wsrs-Signature" // "sha256=" seguido do HMAC-SHA256 de "timestamp.corpo" com o segredo
+)
+
+// WebhookPayload é o corpo enviado pelo servidor aos webhooks.
+// Value tem o formato do evento de mesmo tipo recebido por Subscribe; use DecodeValue para obtê-lo já decodificado.
+type WebhookPayload struct {
+	Version   int             `json:"version"`
+	Kind      string          `json:"kind"`
+	RoomID    uuid.UUID       `json:"room_id"`
+	Value     json.RawMessage `json:"value"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// DecodeValue decodifica Value no tipo correspondente a Kind, como em Event.Value; para tipos desconhecidos, retorna nil.
+func (p WebhookPayload) DecodeValue() (any, error) {
+	return decodeEventValue(p.Kind, p.Value)
+}
+
+// WebhookWithSecret é um webhook recém-criado, com o segredo das assinaturas.
+type WebhookWithSecret struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateWebhookParams descreve um webhook novo.
+type CreateWebhookParams struct {
+	URL        string   `json:"url"`
+	EventKinds []string `json:"event_kinds,omitempty"` // Vazio assina todos os tipos de evento
+}
+
+// CreateRoomWebhook cadastra um webhook na sala (moderador).
+// O segredo das assinaturas só é devolvido nesta resposta.
+func (c *Client) CreateRoomWebhook(ctx context.Context, roomID uuid.UUID, params CreateWebhookParams) (WebhookWithSecret, error) {
+	var webhook WebhookWithSecret
+	err := c.do(ctx, http.MethodPost, roomPath(roomID)+"/webhooks", params, &webhook)
+	return webhook, err
+}
+
+// GetRoomWebhooks lista os webhooks da sala, sem os segredos (moderador).
+func (c *Client) GetRoomWebhooks(ctx context.Context, roomID uuid.UUID) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.do(ctx, http.MethodGet, roomPath(roomID)+"/webhooks", nil, &webhooks)
+	return webhooks, err
+}
+
+// DeleteRoomWebhook remove um webhook e o seu registro de entregas (moderador).
+func (c *Client) DeleteRoomWebhook(ctx context.Context, roomID, webhookID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, roomPath(roomID)+"/webhooks/"+webhookID.String(), nil, nil)
+}
+
+// GetRoomWebhookDeliveries lista as entregas mais recentes dos webhooks da sala (moderador).
+// limit zero usa o padrão do servidor.
+func (c *Client) GetRoomWebhookDeliveries(ctx context.Context, roomID uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	path := roomPath(roomID) + "/webhooks/deliveries"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, http.MethodGet, path, nil, &deliveries)
+	return deliveries, err
+}
+
+// Erros retornados por VerifyWebhook
+var (
+	ErrWebhookSignature = errors.New("invalid webhook signature")
+	ErrWebhookExpired   = errors.New("webhook timestamp outside tolerance")
+)
+
+// VerifyWebhook confere a assinatura de uma entrega recebida e retorna o corpo da requisição.
+// Entregas com o timestamp mais distante do relógio local do que tolerance são recusadas, para evitar repetições.
+func VerifyWebhook(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := r.Header.Get(HeaderWebhookTimestamp)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrWebhookSignature
+	}
+	if d := time.Since(time.Unix(sent, 0)); d > tolerance || d < -tolerance {
+		return nil, ErrWebhookExpired
+	}
+
+	signature, ok := strings.CutPrefix(r.Header.Get(HeaderWebhookSignature), "sha256=")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signWebhook(secret, timestamp, body))) {
+		return nil, ErrWebhookSignature
+	}
+	return body, nil
+}
+
+// signWebhook calcula a assinatura de uma entrega, como o servidor: o HMAC-SHA256 de "timestamp.corpo" em hexadecimal.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}